@@ -0,0 +1,189 @@
+// Package auth issues and verifies the signed session tokens players use to
+// authenticate. Tokens are JWTs signed with HMAC-SHA256 and are checked
+// locally against the shared secret.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/game"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+
+	// MinSecretLength is the shortest secret NewIssuer accepts, the size of
+	// an HMAC-SHA256 output.
+	MinSecretLength = 32
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrShortSecret  = errors.New("secret is too short")
+)
+
+// Kind tells access tokens, which authenticate requests, from refresh tokens,
+// which are only good for getting a new pair.
+type Kind string
+
+const (
+	KindAccess  Kind = "access"
+	KindRefresh Kind = "refresh"
+)
+
+type Claims struct {
+	Subject   string `json:"sub"`
+	Kind      Kind   `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+type TokenPair struct {
+	AccessToken      string    `json:"accessToken"`
+	AccessExpiresAt  time.Time `json:"accessExpiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+type Option func(*Issuer)
+
+func WithClock(clock game.Clock) Option {
+	return func(i *Issuer) {
+		i.clock = clock
+	}
+}
+
+func WithAccessTTL(ttl time.Duration) Option {
+	return func(i *Issuer) {
+		i.accessTTL = ttl
+	}
+}
+
+func WithRefreshTTL(ttl time.Duration) Option {
+	return func(i *Issuer) {
+		i.refreshTTL = ttl
+	}
+}
+
+// Issuer signs and verifies tokens with a single secret.
+type Issuer struct {
+	secret     []byte
+	clock      game.Clock
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewIssuer(secret []byte, opts ...Option) (*Issuer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrShortSecret
+	}
+
+	i := &Issuer{
+		secret:     secret,
+		clock:      game.RealClock{},
+		accessTTL:  DefaultAccessTTL,
+		refreshTTL: DefaultRefreshTTL,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i, nil
+}
+
+// RandomSecret returns a new secret for an issuer whose tokens only need to
+// outlive the process.
+func RandomSecret() []byte {
+	secret := make([]byte, MinSecretLength)
+	rand.Read(secret)
+	return secret
+}
+
+// Issue returns a fresh access and refresh token for the player.
+func (i *Issuer) Issue(playerID string) (TokenPair, error) {
+	now := i.clock.Now()
+
+	access, accessClaims, err := i.sign(playerID, KindAccess, now, i.accessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, refreshClaims, err := i.sign(playerID, KindRefresh, now, i.refreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessClaims.Expiry(),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshClaims.Expiry(),
+	}, nil
+}
+
+// header is the only JOSE header the issuer produces or accepts, which rules
+// out algorithm confusion such as "alg": "none".
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func (i *Issuer) sign(subject string, kind Kind, now time.Time, ttl time.Duration) (string, Claims, error) {
+	claims := Claims{
+		Subject:   subject,
+		Kind:      kind,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + i.signature(unsigned), claims, nil
+}
+
+func (i *Issuer) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the token's signature, kind and expiry and returns its
+// claims.
+func (i *Issuer) Verify(token string, kind Kind) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return Claims{}, ErrInvalidToken
+	}
+
+	unsigned := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(i.signature(unsigned))) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	if claims.Kind != kind || claims.Subject == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	if !i.clock.Now().Before(claims.Expiry()) {
+		return Claims{}, ErrTokenExpired
+	}
+	return claims, nil
+}
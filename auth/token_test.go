@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"errors"
+	"ldriko/rps-backend/game"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T) (*Issuer, *game.MockClock) {
+	t.Helper()
+
+	clock := game.NewMockClock(time.Unix(1000, 0))
+	issuer, err := NewIssuer([]byte(strings.Repeat("s", MinSecretLength)),
+		WithClock(clock), WithAccessTTL(time.Minute), WithRefreshTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return issuer, clock
+}
+
+func TestNewIssuer(t *testing.T) {
+	if _, err := NewIssuer([]byte("short")); !errors.Is(err, ErrShortSecret) {
+		t.Errorf("Expected ErrShortSecret, got %v", err)
+	}
+	if _, err := NewIssuer(RandomSecret()); err != nil {
+		t.Errorf("Expected a random secret to be accepted, got %v", err)
+	}
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	t.Run("Valid tokens", func(t *testing.T) {
+		issuer, clock := newTestIssuer(t)
+		pair, err := issuer.Issue("player1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !pair.AccessExpiresAt.Equal(clock.Now().Add(time.Minute)) {
+			t.Errorf("Expected the access token to last a minute, got %v", pair.AccessExpiresAt)
+		}
+
+		claims, err := issuer.Verify(pair.AccessToken, KindAccess)
+		if err != nil || claims.Subject != "player1" {
+			t.Fatalf("Expected a valid access token for player1, got %+v, %v", claims, err)
+		}
+		if _, err := issuer.Verify(pair.RefreshToken, KindRefresh); err != nil {
+			t.Errorf("Expected a valid refresh token, got %v", err)
+		}
+	})
+
+	t.Run("Kinds are not interchangeable", func(t *testing.T) {
+		issuer, _ := newTestIssuer(t)
+		pair, _ := issuer.Issue("player1")
+		if _, err := issuer.Verify(pair.RefreshToken, KindAccess); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected a refresh token to be refused as an access token, got %v", err)
+		}
+		if _, err := issuer.Verify(pair.AccessToken, KindRefresh); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected an access token to be refused for refreshing, got %v", err)
+		}
+	})
+
+	t.Run("Expired tokens", func(t *testing.T) {
+		issuer, clock := newTestIssuer(t)
+		pair, _ := issuer.Issue("player1")
+		clock.Advance(time.Minute)
+		if _, err := issuer.Verify(pair.AccessToken, KindAccess); !errors.Is(err, ErrTokenExpired) {
+			t.Errorf("Expected ErrTokenExpired, got %v", err)
+		}
+
+		if _, err := issuer.Verify(pair.RefreshToken, KindRefresh); err != nil {
+			t.Fatalf("Expected the refresh token to still work, got %v", err)
+		}
+
+		clock.Advance(time.Hour)
+		if _, err := issuer.Verify(pair.RefreshToken, KindRefresh); !errors.Is(err, ErrTokenExpired) {
+			t.Errorf("Expected ErrTokenExpired, got %v", err)
+		}
+	})
+
+	t.Run("Tampered tokens", func(t *testing.T) {
+		issuer, _ := newTestIssuer(t)
+		pair, _ := issuer.Issue("player1")
+		other, _ := NewIssuer(RandomSecret())
+		forged, _ := other.Issue("player1")
+
+		parts := strings.Split(pair.AccessToken, ".")
+		swapped := parts[0] + "." + strings.Split(forged.AccessToken, ".")[1] + "." + parts[2]
+		none := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0." + parts[1] + "."
+
+		for _, token := range []string{"", "a.b", forged.AccessToken, swapped, none, pair.AccessToken + "x"} {
+			if _, err := issuer.Verify(token, KindAccess); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected %q to be refused, got %v", token, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,129 @@
+// Package bot plays games on the server's side so players always have an
+// opponent, even when nobody else is queueing.
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"ldriko/rps-backend/game"
+	"log"
+	"sync"
+)
+
+// Bot takes a seat in a game like any other player, but reacts to the
+// game's events instead of websocket messages. It can sit in either seat.
+type Bot struct {
+	ID       string
+	Strategy Strategy
+
+	game        *game.Game
+	seat        game.Seat
+	unsubscribe func()
+	stopOnce    sync.Once
+
+	// move and nonce are the pending commitment in commit-reveal rounds.
+	move  game.Move
+	nonce string
+	mu    sync.Mutex
+}
+
+// New creates a bot playing the given strategy, or uniformly random moves
+// when strategy is nil.
+func New(id string, strategy Strategy) *Bot {
+	if strategy == nil {
+		strategy = NewRandom(nil)
+	}
+	return &Bot{ID: id, Strategy: strategy}
+}
+
+// Join seats the bot in the game and plays every round until the game is
+// finished. A game created with the bot as a player keeps it in that seat.
+// bus must be the bus the game publishes to.
+func (b *Bot) Join(gm *game.Game, bus game.EventBus) (game.Seat, error) {
+	b.game = gm
+	b.unsubscribe = bus.Subscribe(b.handleEvent)
+
+	seat, err := gm.AddPlayer(b.ID)
+	if err != nil {
+		b.Stop()
+		return seat, err
+	}
+	b.seat = seat
+
+	gm.SetPlayerConnected(b.ID, true)
+	return seat, nil
+}
+
+// Stop makes the bot stop reacting to the game.
+func (b *Bot) Stop() {
+	b.stopOnce.Do(func() {
+		if b.unsubscribe != nil {
+			b.unsubscribe()
+		}
+	})
+}
+
+// handleEvent runs while the game publishes, so moves are made from a new
+// goroutine rather than mutating the game synchronously.
+func (b *Bot) handleEvent(event game.Event) {
+	if event.GameID() != b.game.ID {
+		return
+	}
+
+	switch e := event.(type) {
+	case game.RoundStarted:
+		if e.Round.Phase == game.PhaseCommit {
+			go b.commit()
+		} else {
+			go b.submit()
+		}
+	case game.RevealPhaseStarted:
+		go b.reveal()
+	case game.GameFinished:
+		b.Stop()
+	}
+}
+
+func (b *Bot) submit() {
+	if _, err := b.game.SubmitMove(b.ID, b.chooseMove()); err != nil {
+		log.Printf("bot %s failed to move in game %s: %v", b.ID, b.game.ID, err)
+	}
+}
+
+func (b *Bot) commit() {
+	b.mu.Lock()
+	b.move = b.chooseMove()
+	b.nonce = newNonce()
+	commitment := game.HashMove(b.move, b.nonce)
+	b.mu.Unlock()
+
+	if _, err := b.game.CommitMove(b.ID, commitment); err != nil {
+		log.Printf("bot %s failed to commit in game %s: %v", b.ID, b.game.ID, err)
+	}
+}
+
+func (b *Bot) reveal() {
+	b.mu.Lock()
+	move, nonce := b.move, b.nonce
+	b.mu.Unlock()
+
+	if _, err := b.game.RevealMove(b.ID, move, nonce); err != nil {
+		log.Printf("bot %s failed to reveal in game %s: %v", b.ID, b.game.ID, err)
+	}
+}
+
+func (b *Bot) chooseMove() game.Move {
+	return b.Strategy.Move(History{
+		Rules:  b.game.Rules(),
+		Rounds: b.game.History(),
+		Seat:   b.seat,
+	})
+}
+
+func newNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
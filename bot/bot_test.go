@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"ldriko/rps-backend/game"
+	"testing"
+	"time"
+)
+
+// waitFor polls until cond holds, since bots move from their own goroutine.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the bot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newBotGame(t *testing.T, config game.MatchConfig) (*game.Game, *Bot) {
+	t.Helper()
+
+	gm := game.NewManager(game.WithClock(game.NewMockClock(time.Unix(0, 0))))
+	g, err := gm.CreateGame("human", "", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := New("bot", nil)
+	seat, err := b.Join(g, gm.Events())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seat != game.SeatP2 {
+		t.Fatalf("Expected bot in seat p2, got %s", seat)
+	}
+	return g, b
+}
+
+func TestBotPlaysRounds(t *testing.T) {
+	g, _ := newBotGame(t, game.DefaultMatchConfig())
+
+	if g.SpectatorView().State != game.StateReady {
+		t.Fatalf("Expected game to be ready, got %s", g.SpectatorView().State)
+	}
+	if !g.SpectatorView().P2Connected {
+		t.Fatal("Expected bot to be marked connected")
+	}
+
+	for !g.IsOver() {
+		number := len(g.SpectatorView().Rounds) + 1
+		if _, err := g.NewRound(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := g.SubmitMove("human", game.Rock); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		waitFor(t, func() bool { return len(g.SpectatorView().Rounds) >= number })
+	}
+}
+
+func TestBotCommitReveal(t *testing.T) {
+	config := game.DefaultMatchConfig()
+	config.CommitReveal = true
+	g, _ := newBotGame(t, config)
+
+	if _, err := g.NewRound(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := g.CommitMove("human", game.HashMove(game.Rock, "nonce")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		round := g.SpectatorView().CurrentRound
+		return round != nil && round.Phase == game.PhaseReveal
+	})
+
+	if _, err := g.RevealMove("human", game.Rock, "nonce"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(g.SpectatorView().Rounds) == 1 })
+
+	round := g.SpectatorView().Rounds[0]
+	for _, entry := range round.Transcript {
+		if entry.Player == "bot" && !entry.Valid {
+			t.Fatalf("Expected the bot's transcript entries to be valid, got %+v", entry)
+		}
+	}
+}
+
+func TestBotStopsWhenGameFinishes(t *testing.T) {
+	config := game.DefaultMatchConfig()
+	config.Rounds = 1
+	g, b := newBotGame(t, config)
+
+	g.NewRound()
+	g.SubmitMove("human", game.Rock)
+	waitFor(t, g.IsOver)
+
+	stopped := make(chan struct{})
+	go func() {
+		b.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Stop to return once the game finished")
+	}
+}
+
+func TestBotAsP1(t *testing.T) {
+	gm := game.NewManager(game.WithClock(game.NewMockClock(time.Unix(0, 0))))
+	g, err := gm.CreateGame("bot", "human", game.DefaultMatchConfig())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := New("bot", NewFrequency(nil))
+	seat, err := b.Join(g, gm.Events())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seat != game.SeatP1 {
+		t.Fatalf("Expected bot in seat p1, got %s", seat)
+	}
+
+	g.NewRound()
+	g.SubmitMove("human", game.Paper)
+	waitFor(t, func() bool { return len(g.SpectatorView().Rounds) == 1 })
+
+	if move := g.SpectatorView().Rounds[0].P1; !game.Classic.IsValidMove(move) {
+		t.Fatalf("Expected the bot to play a valid move, got %q", move)
+	}
+}
@@ -0,0 +1,283 @@
+package bot
+
+import (
+	"ldriko/rps-backend/game"
+	"math/rand/v2"
+)
+
+// History is what a strategy knows when choosing a move: the rules, the
+// finished rounds and which seat the bot plays.
+type History struct {
+	Rules  *game.RuleSet
+	Rounds []game.Round
+	Seat   game.Seat
+}
+
+// OwnMove and OpponentMove return the bot's and the opponent's move in the
+// given round. Either is empty when that player timed out.
+func (h History) OwnMove(r game.Round) game.Move {
+	if h.Seat == game.SeatP2 {
+		return r.P2
+	}
+	return r.P1
+}
+
+func (h History) OpponentMove(r game.Round) game.Move {
+	if h.Seat == game.SeatP2 {
+		return r.P1
+	}
+	return r.P2
+}
+
+// OpponentMoves lists the opponent's moves in order, skipping timeouts.
+func (h History) OpponentMoves() []game.Move {
+	moves := make([]game.Move, 0, len(h.Rounds))
+	for _, r := range h.Rounds {
+		if m := h.OpponentMove(r); m != "" {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// Strategy picks the bot's next move from the game so far.
+type Strategy interface {
+	Name() string
+	Move(h History) game.Move
+}
+
+// Rand is the source of randomness strategies use, so tests can make them
+// deterministic. *rand.Rand satisfies it.
+type Rand interface {
+	IntN(n int) int
+}
+
+type globalRand struct{}
+
+func (globalRand) IntN(n int) int {
+	return rand.IntN(n)
+}
+
+func orGlobal(rng Rand) Rand {
+	if rng == nil {
+		return globalRand{}
+	}
+	return rng
+}
+
+func randomMove(rules *game.RuleSet, rng Rand) game.Move {
+	return rules.Moves[rng.IntN(len(rules.Moves))]
+}
+
+// counter returns a move that beats target, picking randomly when several
+// do.
+func counter(rules *game.RuleSet, target game.Move, rng Rand) game.Move {
+	var winners []game.Move
+	for _, m := range rules.Moves {
+		if rules.Beats(m, target) {
+			winners = append(winners, m)
+		}
+	}
+	if len(winners) == 0 {
+		return randomMove(rules, rng)
+	}
+	return winners[rng.IntN(len(winners))]
+}
+
+// Random plays uniformly random moves, which cannot be exploited.
+type Random struct {
+	rng Rand
+}
+
+func NewRandom(rng Rand) *Random {
+	return &Random{rng: orGlobal(rng)}
+}
+
+func (s *Random) Name() string {
+	return "random"
+}
+
+func (s *Random) Move(h History) game.Move {
+	return randomMove(h.Rules, s.rng)
+}
+
+// Frequency counters the move the opponent has played most often.
+type Frequency struct {
+	rng Rand
+}
+
+func NewFrequency(rng Rand) *Frequency {
+	return &Frequency{rng: orGlobal(rng)}
+}
+
+func (s *Frequency) Name() string {
+	return "frequency"
+}
+
+func (s *Frequency) Move(h History) game.Move {
+	counts := make(map[game.Move]int)
+	for _, m := range h.OpponentMoves() {
+		counts[m]++
+	}
+
+	favourite, best := game.Move(""), 0
+	for _, m := range h.Rules.Moves {
+		if counts[m] > best {
+			favourite, best = m, counts[m]
+		}
+	}
+	if favourite == "" {
+		return randomMove(h.Rules, s.rng)
+	}
+	return counter(h.Rules, favourite, s.rng)
+}
+
+// Markov predicts the opponent's next move from what followed their last
+// Order moves earlier in the game, and counters it.
+type Markov struct {
+	Order int
+	rng   Rand
+}
+
+func NewMarkov(order int, rng Rand) *Markov {
+	if order < 1 {
+		order = 1
+	}
+	return &Markov{Order: order, rng: orGlobal(rng)}
+}
+
+func (s *Markov) Name() string {
+	return "markov"
+}
+
+func (s *Markov) Move(h History) game.Move {
+	moves := h.OpponentMoves()
+	if len(moves) <= s.Order {
+		return randomMove(h.Rules, s.rng)
+	}
+
+	last := moves[len(moves)-s.Order:]
+	next := make(map[game.Move]int)
+	for i := 0; i+s.Order < len(moves); i++ {
+		if equalMoves(moves[i:i+s.Order], last) {
+			next[moves[i+s.Order]]++
+		}
+	}
+
+	predicted, best := game.Move(""), 0
+	for _, m := range h.Rules.Moves {
+		if next[m] > best {
+			predicted, best = m, next[m]
+		}
+	}
+	if predicted == "" {
+		return randomMove(h.Rules, s.rng)
+	}
+	return counter(h.Rules, predicted, s.rng)
+}
+
+func equalMoves(a, b []game.Move) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WinStayLoseShift repeats a winning move and otherwise switches to the move
+// that would have beaten the opponent's last one.
+type WinStayLoseShift struct {
+	rng Rand
+}
+
+func NewWinStayLoseShift(rng Rand) *WinStayLoseShift {
+	return &WinStayLoseShift{rng: orGlobal(rng)}
+}
+
+func (s *WinStayLoseShift) Name() string {
+	return "win_stay_lose_shift"
+}
+
+func (s *WinStayLoseShift) Move(h History) game.Move {
+	if len(h.Rounds) == 0 {
+		return randomMove(h.Rules, s.rng)
+	}
+
+	last := h.Rounds[len(h.Rounds)-1]
+	own, opponent := h.OwnMove(last), h.OpponentMove(last)
+	if own != "" && h.Rules.Beats(own, opponent) {
+		return own
+	}
+	if opponent == "" {
+		return randomMove(h.Rules, s.rng)
+	}
+	return counter(h.Rules, opponent, s.rng)
+}
+
+// Meta replays the game so far through each of its strategies and follows
+// the one that would have scored best over the last Window rounds, so it
+// adapts when the opponent changes their habits.
+type Meta struct {
+	Strategies []Strategy
+	Window     int
+}
+
+func NewMeta(window int, strategies ...Strategy) *Meta {
+	return &Meta{Strategies: strategies, Window: window}
+}
+
+func (s *Meta) Name() string {
+	return "meta"
+}
+
+func (s *Meta) Move(h History) game.Move {
+	return s.Best(h).Move(h)
+}
+
+// Best returns the strategy Meta would follow for the next move. Ties go to
+// the strategy listed first, and a Meta without strategies plays randomly.
+func (s *Meta) Best(h History) Strategy {
+	if len(s.Strategies) == 0 {
+		return NewRandom(nil)
+	}
+
+	start := 0
+	if s.Window > 0 && len(h.Rounds) > s.Window {
+		start = len(h.Rounds) - s.Window
+	}
+
+	best, bestScore := s.Strategies[0], 0
+	for i, strategy := range s.Strategies {
+		score := 0
+		for r := start; r < len(h.Rounds); r++ {
+			past := History{Rules: h.Rules, Rounds: h.Rounds[:r], Seat: h.Seat}
+			move := strategy.Move(past)
+			opponent := h.OpponentMove(h.Rounds[r])
+
+			switch {
+			case h.Rules.Beats(move, opponent):
+				score++
+			case h.Rules.Beats(opponent, move):
+				score--
+			}
+		}
+
+		if i == 0 || score > bestScore {
+			best, bestScore = strategy, score
+		}
+	}
+	return best
+}
+
+// DefaultStrategy mixes every strategy under a meta-strategy that follows
+// whichever has done best over the last ten rounds.
+func DefaultStrategy() Strategy {
+	return NewMeta(10,
+		NewRandom(nil),
+		NewFrequency(nil),
+		NewMarkov(1, nil),
+		NewMarkov(2, nil),
+		NewWinStayLoseShift(nil),
+	)
+}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"ldriko/rps-backend/game"
+	"math/rand/v2"
+	"testing"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+// history builds the history of a bot in seat p1 from (bot, opponent) pairs.
+func history(rules *game.RuleSet, moves ...[2]game.Move) History {
+	rounds := make([]game.Round, len(moves))
+	for i, m := range moves {
+		rounds[i] = game.Round{Number: i + 1, P1: m[0], P2: m[1]}
+	}
+	return History{Rules: rules, Rounds: rounds, Seat: game.SeatP1}
+}
+
+func TestRandom(t *testing.T) {
+	s := NewRandom(newRand())
+	seen := make(map[game.Move]bool)
+	for i := 0; i < 100; i++ {
+		move := s.Move(history(game.RPSLS))
+		if !game.RPSLS.IsValidMove(move) {
+			t.Fatalf("Unexpected move %q", move)
+		}
+		seen[move] = true
+	}
+	if len(seen) != len(game.RPSLS.Moves) {
+		t.Fatalf("Expected every move to be played, got %v", seen)
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	s := NewFrequency(newRand())
+	h := history(game.Classic,
+		[2]game.Move{game.Paper, game.Rock},
+		[2]game.Move{game.Paper, game.Scissors},
+		[2]game.Move{game.Paper, game.Rock},
+	)
+
+	if move := s.Move(h); move != game.Paper {
+		t.Fatalf("Expected paper to counter a rock-heavy opponent, got %s", move)
+	}
+}
+
+func TestMarkov(t *testing.T) {
+	s := NewMarkov(1, newRand())
+	// The opponent always follows rock with paper.
+	h := history(game.Classic,
+		[2]game.Move{game.Rock, game.Rock},
+		[2]game.Move{game.Rock, game.Paper},
+		[2]game.Move{game.Rock, game.Rock},
+		[2]game.Move{game.Rock, game.Paper},
+		[2]game.Move{game.Rock, game.Rock},
+	)
+
+	if move := s.Move(h); move != game.Scissors {
+		t.Fatalf("Expected scissors to counter the predicted paper, got %s", move)
+	}
+}
+
+func TestWinStayLoseShift(t *testing.T) {
+	s := NewWinStayLoseShift(newRand())
+
+	t.Run("Stay after a win", func(t *testing.T) {
+		h := history(game.Classic, [2]game.Move{game.Rock, game.Scissors})
+		if move := s.Move(h); move != game.Rock {
+			t.Fatalf("Expected rock, got %s", move)
+		}
+	})
+
+	t.Run("Shift after a loss", func(t *testing.T) {
+		h := history(game.Classic, [2]game.Move{game.Rock, game.Paper})
+		if move := s.Move(h); move != game.Scissors {
+			t.Fatalf("Expected scissors, got %s", move)
+		}
+	})
+
+	t.Run("Seat p2", func(t *testing.T) {
+		h := history(game.Classic, [2]game.Move{game.Paper, game.Rock})
+		h.Seat = game.SeatP2
+		if move := s.Move(h); move != game.Scissors {
+			t.Fatalf("Expected scissors, got %s", move)
+		}
+	})
+}
+
+type fixed game.Move
+
+func (s fixed) Name() string {
+	return string(s)
+}
+
+func (s fixed) Move(History) game.Move {
+	return game.Move(s)
+}
+
+func TestMeta(t *testing.T) {
+	s := NewMeta(3, fixed(game.Scissors), fixed(game.Paper))
+
+	t.Run("Follows the best strategy", func(t *testing.T) {
+		h := history(game.Classic,
+			[2]game.Move{game.Scissors, game.Rock},
+			[2]game.Move{game.Scissors, game.Rock},
+		)
+		if move := s.Move(h); move != game.Paper {
+			t.Fatalf("Expected paper, got %s", move)
+		}
+	})
+
+	t.Run("Only the window counts", func(t *testing.T) {
+		h := history(game.Classic,
+			[2]game.Move{game.Scissors, game.Rock},
+			[2]game.Move{game.Scissors, game.Rock},
+			[2]game.Move{game.Scissors, game.Rock},
+			[2]game.Move{game.Scissors, game.Paper},
+			[2]game.Move{game.Scissors, game.Paper},
+			[2]game.Move{game.Scissors, game.Paper},
+		)
+		if best := s.Best(h); best.Name() != string(game.Scissors) {
+			t.Fatalf("Expected meta to follow scissors, got %s", best.Name())
+		}
+	})
+
+	t.Run("No history uses the first strategy", func(t *testing.T) {
+		if move := s.Move(history(game.Classic)); move != game.Scissors {
+			t.Fatalf("Expected scissors, got %s", move)
+		}
+	})
+
+	t.Run("No strategies plays randomly", func(t *testing.T) {
+		h := history(game.Classic, [2]game.Move{game.Rock, game.Paper})
+		for _, s := range []*Meta{NewMeta(10), {}, {Window: 3}} {
+			if best := s.Best(h); best.Name() != "random" {
+				t.Fatalf("Expected meta to follow random, got %s", best.Name())
+			}
+			if move := s.Move(h); !game.Classic.IsValidMove(move) {
+				t.Fatalf("Unexpected move %q", move)
+			}
+		}
+	})
+}
+
+func TestDefaultStrategy(t *testing.T) {
+	s := DefaultStrategy()
+	h := history(game.RPS15)
+	for i := 0; i < 20; i++ {
+		move := s.Move(h)
+		if !game.RPS15.IsValidMove(move) {
+			t.Fatalf("Unexpected move %q", move)
+		}
+		h.Rounds = append(h.Rounds, game.Round{Number: i + 1, P1: move, P2: game.RPS15.Moves[i%3]})
+	}
+}
@@ -0,0 +1,118 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/players"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	accountsBucket  = []byte("accounts")
+	usernamesBucket = []byte("usernames")
+)
+
+// PlayerRepository keeps players in the store's database, each as a JSON
+// record, with an index from username key to player ID.
+type PlayerRepository struct {
+	db *bolt.DB
+}
+
+var _ players.Repository = (*PlayerRepository)(nil)
+
+// Players returns a repository sharing the store's database file.
+func (s *Store) Players() *PlayerRepository {
+	return &PlayerRepository{db: s.db}
+}
+
+func (r *PlayerRepository) Create(player models.Player) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(accountsBucket).Get([]byte(player.ID)) != nil {
+			return errors.New("player already exists")
+		}
+		return putPlayer(tx, player, "")
+	})
+}
+
+func (r *PlayerRepository) Get(id string) (models.Player, error) {
+	var player models.Player
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		player, err = getPlayer(tx, []byte(id))
+		return err
+	})
+	return player, err
+}
+
+func (r *PlayerRepository) GetByUsername(username string) (models.Player, error) {
+	var player models.Player
+	err := r.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(usernamesBucket).Get([]byte(players.UsernameKey(username)))
+		if id == nil {
+			return players.ErrPlayerNotFound
+		}
+
+		var err error
+		player, err = getPlayer(tx, id)
+		return err
+	})
+	return player, err
+}
+
+func (r *PlayerRepository) Update(player models.Player) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		old, err := getPlayer(tx, []byte(player.ID))
+		if err != nil {
+			return err
+		}
+		return putPlayer(tx, player, old.Username)
+	})
+}
+
+// putPlayer writes the player and moves its username index entry from
+// oldUsername, failing if another player holds the new username.
+func putPlayer(tx *bolt.Tx, player models.Player, oldUsername string) error {
+	usernames := tx.Bucket(usernamesBucket)
+	key := []byte(players.UsernameKey(player.Username))
+	if id := usernames.Get(key); id != nil && string(id) != player.ID {
+		return players.ErrUsernameTaken
+	}
+
+	data, err := json.Marshal(playerRecord{Player: player, PasswordHash: player.PasswordHash})
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(accountsBucket).Put([]byte(player.ID), data); err != nil {
+		return err
+	}
+
+	if oldUsername != "" {
+		if err := usernames.Delete([]byte(players.UsernameKey(oldUsername))); err != nil {
+			return err
+		}
+	}
+	return usernames.Put(key, []byte(player.ID))
+}
+
+func getPlayer(tx *bolt.Tx, id []byte) (models.Player, error) {
+	data := tx.Bucket(accountsBucket).Get(id)
+	if data == nil {
+		return models.Player{}, players.ErrPlayerNotFound
+	}
+
+	var record playerRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return models.Player{}, err
+	}
+	record.Player.PasswordHash = record.PasswordHash
+	return record.Player, nil
+}
+
+// playerRecord stores the password hash that models.Player leaves out of its
+// JSON.
+type playerRecord struct {
+	models.Player
+	PasswordHash string `json:"passwordHash"`
+}
@@ -0,0 +1,247 @@
+// Package boltstore stores games and players in an embedded bbolt database
+// file.
+package boltstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"ldriko/rps-backend/game"
+	"slices"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	gamesBucket   = []byte("games")
+	roundsBucket  = []byte("rounds")
+	playersBucket = []byte("players")
+	eventsBucket  = []byte("events")
+)
+
+// Store keeps each game as a JSON record, its rounds in a per-game bucket
+// keyed by round number, its events in a per-game bucket keyed by sequence,
+// and an index of game IDs per player.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ game.Store = (*Store)(nil)
+
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{gamesBucket, roundsBucket, playersBucket, eventsBucket, accountsBucket, usernamesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) SaveGame(record game.GameRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return saveGame(tx, record)
+	})
+}
+
+func saveGame(tx *bolt.Tx, record game.GameRecord) error {
+	record.Rounds = nil
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(gamesBucket).Put([]byte(record.ID), data); err != nil {
+		return err
+	}
+
+	for _, player := range []string{record.P1, record.P2} {
+		if player == "" {
+			continue
+		}
+		index, err := tx.Bucket(playersBucket).CreateBucketIfNotExists([]byte(player))
+		if err != nil {
+			return err
+		}
+		if err := index.Put([]byte(record.ID), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) LoadGame(id string) (game.GameRecord, error) {
+	var record game.GameRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		record, err = load(tx, []byte(id))
+		return err
+	})
+	return record, err
+}
+
+func load(tx *bolt.Tx, id []byte) (game.GameRecord, error) {
+	data := tx.Bucket(gamesBucket).Get(id)
+	if data == nil {
+		return game.GameRecord{}, game.ErrGameNotFound
+	}
+
+	var record game.GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return game.GameRecord{}, err
+	}
+
+	record.Rounds = []game.Round{}
+	rounds := tx.Bucket(roundsBucket).Bucket(id)
+	if rounds == nil {
+		return record, nil
+	}
+
+	err := rounds.ForEach(func(_, v []byte) error {
+		var round game.Round
+		if err := json.Unmarshal(v, &round); err != nil {
+			return err
+		}
+		record.Rounds = append(record.Rounds, round)
+		return nil
+	})
+	return record, err
+}
+
+func (s *Store) ListGamesByPlayer(playerID string) ([]game.GameRecord, error) {
+	var records []game.GameRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(playersBucket).Bucket([]byte(playerID))
+		if index == nil {
+			return nil
+		}
+
+		return index.ForEach(func(id, _ []byte) error {
+			record, err := load(tx, id)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	sortByCreation(records)
+	return records, err
+}
+
+func (s *Store) ListUnfinishedGames() ([]game.GameRecord, error) {
+	var records []game.GameRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(id, _ []byte) error {
+			record, err := load(tx, id)
+			if err != nil {
+				return err
+			}
+			if !record.State.IsTerminal() {
+				records = append(records, record)
+			}
+			return nil
+		})
+	})
+	sortByCreation(records)
+	return records, err
+}
+
+// AppendRound stores a finished round. Storing the same round number again
+// replaces it.
+func (s *Store) AppendRound(gameID string, round game.Round) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return appendRound(tx, gameID, round)
+	})
+}
+
+func appendRound(tx *bolt.Tx, gameID string, round game.Round) error {
+	data, err := json.Marshal(round)
+	if err != nil {
+		return err
+	}
+
+	rounds, err := tx.Bucket(roundsBucket).CreateBucketIfNotExists([]byte(gameID))
+	if err != nil {
+		return err
+	}
+	return rounds.Put(sequenceKey(uint64(round.Number)), data)
+}
+
+// SaveEvent writes the event, the round it resolved and the game record in
+// one transaction, so every event costs a single sync to disk.
+func (s *Store) SaveEvent(record game.GameRecord, event game.Event) error {
+	data, err := game.MarshalEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		events, err := tx.Bucket(eventsBucket).CreateBucketIfNotExists([]byte(record.ID))
+		if err != nil {
+			return err
+		}
+		seq, err := events.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := events.Put(sequenceKey(seq), data); err != nil {
+			return err
+		}
+
+		if e, ok := event.(game.RoundResolved); ok {
+			if err := appendRound(tx, record.ID, e.Round); err != nil {
+				return err
+			}
+		}
+		return saveGame(tx, record)
+	})
+}
+
+func (s *Store) LoadEvents(gameID string) ([]game.Event, error) {
+	var log []game.Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		events := tx.Bucket(eventsBucket).Bucket([]byte(gameID))
+		if events == nil {
+			return nil
+		}
+
+		return events.ForEach(func(_, v []byte) error {
+			event, err := game.UnmarshalEvent(v)
+			if err != nil {
+				return err
+			}
+			log = append(log, event)
+			return nil
+		})
+	})
+	return log, err
+}
+
+func sequenceKey(n uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return key
+}
+
+func sortByCreation(records []game.GameRecord) {
+	slices.SortFunc(records, func(a, b game.GameRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
@@ -0,0 +1,184 @@
+package boltstore
+
+import (
+	"errors"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/players"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore(t *testing.T) {
+	t.Run("Save and load", func(t *testing.T) {
+		s := openStore(t, filepath.Join(t.TempDir(), "rps.db"))
+
+		current := &game.Round{Number: 3, Phase: game.PhaseMove, P1: game.Rock}
+		record := game.GameRecord{
+			ID:           "game1",
+			P1:           "Alice",
+			P2:           "Bob",
+			State:        game.StateRoundInProgress,
+			Config:       game.DefaultMatchConfig(),
+			CurrentRound: current,
+			P1Wins:       1,
+			CreatedAt:    time.Unix(100, 0).UTC(),
+		}
+		if err := s.SaveGame(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for i, winner := range []string{"p1", ""} {
+			if err := s.AppendRound("game1", game.Round{Number: i + 1, Winner: winner}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		loaded, err := s.LoadGame("game1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if loaded.P1 != "Alice" || loaded.P1Wins != 1 || !loaded.CreatedAt.Equal(record.CreatedAt) {
+			t.Errorf("Unexpected record %+v", loaded)
+		}
+		if loaded.CurrentRound == nil || loaded.CurrentRound.P1 != game.Rock {
+			t.Errorf("Expected the current round to be stored, got %+v", loaded.CurrentRound)
+		}
+		if len(loaded.Rounds) != 2 || loaded.Rounds[0].Winner != "p1" || loaded.Rounds[1].Number != 2 {
+			t.Errorf("Expected rounds in order, got %+v", loaded.Rounds)
+		}
+	})
+
+	t.Run("Missing game", func(t *testing.T) {
+		s := openStore(t, filepath.Join(t.TempDir(), "rps.db"))
+		if _, err := s.LoadGame("missing"); !errors.Is(err, game.ErrGameNotFound) {
+			t.Fatalf("Expected ErrGameNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		s := openStore(t, filepath.Join(t.TempDir(), "rps.db"))
+		s.SaveGame(game.GameRecord{ID: "game1", P1: "Alice", P2: "Bob", State: game.StateFinished, CreatedAt: time.Unix(1, 0)})
+		s.SaveGame(game.GameRecord{ID: "game2", P1: "Bob", State: game.StateWaitingForOpponent, CreatedAt: time.Unix(2, 0)})
+		s.SaveGame(game.GameRecord{ID: "game2", P1: "Bob", P2: "Carol", State: game.StateReady, CreatedAt: time.Unix(2, 0)})
+
+		bob, err := s.ListGamesByPlayer("Bob")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(bob) != 2 || bob[0].ID != "game1" || bob[1].ID != "game2" {
+			t.Errorf("Expected game1 and game2 for Bob, got %+v", bob)
+		}
+
+		carol, _ := s.ListGamesByPlayer("Carol")
+		if len(carol) != 1 {
+			t.Errorf("Expected Carol to be indexed once she was seated, got %d games", len(carol))
+		}
+
+		unfinished, _ := s.ListUnfinishedGames()
+		if len(unfinished) != 1 || unfinished[0].ID != "game2" {
+			t.Errorf("Expected only game2 to be unfinished, got %+v", unfinished)
+		}
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		s := openStore(t, filepath.Join(t.TempDir(), "rps.db"))
+
+		m := game.NewManager(game.WithStore(s))
+		g, _ := m.CreateGame("Alice", "Bob", game.DefaultMatchConfig())
+		g.NewRound()
+		g.SubmitMove("Alice", game.Rock)
+		g.SubmitMove("Bob", game.Scissors)
+
+		events, err := s.LoadEvents(g.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		replayed, err := game.Replay(events)
+		if err != nil {
+			t.Fatalf("Expected stored events to replay, got %v", err)
+		}
+		if replayed.P1Wins != 1 || len(replayed.Rounds) != 1 || replayed.State != g.State {
+			t.Errorf("Expected the replayed game to match, got %+v", replayed.Record())
+		}
+
+		// Each event is stored with the record and round it produced.
+		record, err := s.LoadGame(g.ID)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if record.State != g.State || record.P1Wins != 1 || len(record.Rounds) != 1 || record.Rounds[0].Winner != "p1" {
+			t.Errorf("Expected the stored record to match the game, got %+v", record)
+		}
+
+		if events, _ := s.LoadEvents("missing"); len(events) != 0 {
+			t.Errorf("Expected no events for a missing game, got %d", len(events))
+		}
+	})
+
+	t.Run("Survives reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rps.db")
+		s, err := Open(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		s.SaveGame(game.GameRecord{ID: "game1", P1: "Alice", State: game.StateWaitingForOpponent})
+		s.Close()
+
+		reopened := openStore(t, path)
+		if _, err := reopened.LoadGame("game1"); err != nil {
+			t.Fatalf("Expected game to survive reopening, got %v", err)
+		}
+	})
+}
+
+func TestPlayerRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rps.db")
+	s := openStore(t, path)
+	repo := s.Players()
+
+	alice := models.Player{ID: "player1", Username: "alice", CreatedAt: 1000, PasswordHash: "hash"}
+	if err := repo.Create(alice); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := repo.Create(models.Player{ID: "player2", Username: "Alice"}); !errors.Is(err, players.ErrUsernameTaken) {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+	if err := repo.Create(models.Player{ID: "player2", Username: "bob"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alice.Username = "alicia"
+	if err := repo.Update(alice); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := repo.GetByUsername("alice"); !errors.Is(err, players.ErrPlayerNotFound) {
+		t.Errorf("Expected the old username to be released, got %v", err)
+	}
+	alice.Username = "BOB"
+	if err := repo.Update(alice); !errors.Is(err, players.ErrUsernameTaken) {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+	if err := repo.Update(models.Player{ID: "missing", Username: "carol"}); !errors.Is(err, players.ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+
+	found, err := repo.GetByUsername("ALICIA")
+	if err != nil || found.ID != "player1" || found.CreatedAt != 1000 || found.PasswordHash != "hash" {
+		t.Errorf("Expected to find player1 by username, got %+v, %v", found, err)
+	}
+	if _, err := repo.Get("missing"); !errors.Is(err, players.ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	Stop() bool
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// MockClock is a manually advanced clock. Timers fire synchronously from
+// Advance once the clock reaches their deadline.
+type MockClock struct {
+	now    time.Time
+	timers []*mockTimer
+	mu     sync.Mutex
+}
+
+type mockTimer struct {
+	clock    *MockClock
+	deadline time.Time
+	f        func()
+	stopped  bool
+}
+
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now}
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *MockClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &mockTimer{clock: c, deadline: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var due, pending []*mockTimer
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.stopped = true
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *mockTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMockClock(t *testing.T) {
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Advance moves time forward", func(t *testing.T) {
+		clock := NewMockClock(start)
+		clock.Advance(time.Minute)
+		if !clock.Now().Equal(start.Add(time.Minute)) {
+			t.Errorf("Expected %v, got %v", start.Add(time.Minute), clock.Now())
+		}
+	})
+
+	t.Run("Timers fire in deadline order", func(t *testing.T) {
+		clock := NewMockClock(start)
+		var fired []int
+		clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+		clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+		clock.AfterFunc(time.Minute, func() { fired = append(fired, 3) })
+
+		clock.Advance(5 * time.Second)
+		if len(fired) != 2 || fired[0] != 1 || fired[1] != 2 {
+			t.Errorf("Expected timers [1 2] to fire, got %v", fired)
+		}
+	})
+
+	t.Run("Stopped timers do not fire", func(t *testing.T) {
+		clock := NewMockClock(start)
+		fired := false
+		timer := clock.AfterFunc(time.Second, func() { fired = true })
+		if !timer.Stop() {
+			t.Errorf("Expected Stop to report an active timer")
+		}
+		clock.Advance(time.Minute)
+		if fired {
+			t.Errorf("Expected stopped timer not to fire")
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// DefaultRevealTimeout is how long players have to reveal in games without a
+// move timeout.
+const DefaultRevealTimeout = 30 * time.Second
+
+const (
+	ActionCommit  = "commit"
+	ActionReveal  = "reveal"
+	ActionTimeout = "timeout"
+)
+
+// HashMove returns the commitment for a move, the hex encoded SHA-256 of the
+// move and nonce joined by a colon.
+func HashMove(move Move, nonce string) string {
+	sum := sha256.Sum256([]byte(string(move) + ":" + nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func (g *Game) CommitMove(player, commitment string) (MoveResult, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	round := g.CurrentRound
+	if !g.Config.CommitReveal {
+		return MoveResult{}, errors.New("game does not use commit-reveal")
+	} else if g.isOver() {
+		return MoveResult{}, errors.New("game over")
+	} else if round == nil {
+		return MoveResult{}, errors.New("no active round")
+	} else if round.Phase != PhaseCommit {
+		return MoveResult{}, errors.New("round is not accepting commitments")
+	} else if commitment == "" {
+		return MoveResult{}, errors.New("invalid commitment")
+	}
+
+	switch player {
+	case g.P1:
+		if round.P1Commit != "" {
+			return MoveResult{}, errors.New("move already committed")
+		}
+		round.P1Commit = commitment
+	case g.P2:
+		if round.P2Commit != "" {
+			return MoveResult{}, errors.New("move already committed")
+		}
+		round.P2Commit = commitment
+	default:
+		return MoveResult{}, errors.New("not a player in this game")
+	}
+
+	now := g.clock.Now()
+	round.Transcript = append(round.Transcript, TranscriptEntry{
+		Player:     player,
+		Action:     ActionCommit,
+		Commitment: commitment,
+		Valid:      true,
+		At:         now,
+	})
+	round.UpdatedAt = now
+	g.LastActivity = now
+	g.emit(MoveCommitted{EventMeta: g.metaAt(now), PlayerID: player, Round: round.Number, Commitment: commitment})
+
+	if round.P1Commit != "" && round.P2Commit != "" {
+		g.startRevealPhase()
+	}
+	return g.moveResult(round.Number), nil
+}
+
+// RevealMove checks a revealed move against the player's commitment. A
+// reveal that does not match, or names a move outside the rule set, forfeits
+// the round to the opponent.
+func (g *Game) RevealMove(player string, move Move, nonce string) (MoveResult, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	round := g.CurrentRound
+	if !g.Config.CommitReveal {
+		return MoveResult{}, errors.New("game does not use commit-reveal")
+	} else if g.isOver() {
+		return MoveResult{}, errors.New("game over")
+	} else if round == nil {
+		return MoveResult{}, errors.New("no active round")
+	} else if round.Phase != PhaseReveal {
+		return MoveResult{}, errors.New("round is not accepting reveals")
+	}
+
+	var commitment string
+	var revealed Move
+	switch player {
+	case g.P1:
+		commitment, revealed = round.P1Commit, round.P1
+	case g.P2:
+		commitment, revealed = round.P2Commit, round.P2
+	default:
+		return MoveResult{}, errors.New("not a player in this game")
+	}
+	if commitment == "" {
+		return MoveResult{}, errors.New("no commitment to reveal")
+	} else if revealed != "" {
+		return MoveResult{}, errors.New("move already revealed")
+	}
+
+	valid := g.rules.IsValidMove(move) &&
+		subtle.ConstantTimeCompare([]byte(HashMove(move, nonce)), []byte(commitment)) == 1
+
+	now := g.clock.Now()
+	round.Transcript = append(round.Transcript, TranscriptEntry{
+		Player: player,
+		Action: ActionReveal,
+		Move:   move,
+		Nonce:  nonce,
+		Valid:  valid,
+		At:     now,
+	})
+	round.UpdatedAt = now
+	g.LastActivity = now
+	g.emit(MoveRevealed{
+		EventMeta: g.metaAt(now),
+		PlayerID:  player,
+		Round:     round.Number,
+		Move:      move,
+		Nonce:     nonce,
+		Valid:     valid,
+	})
+
+	if !valid {
+		winner := "p1"
+		if player == g.P1 {
+			winner = "p2"
+		}
+		if err := g.finishRound(round.P1, round.P2, winner); err != nil {
+			return MoveResult{}, err
+		}
+		return g.moveResult(round.Number), nil
+	}
+
+	if player == g.P1 {
+		round.P1 = move
+	} else {
+		round.P2 = move
+	}
+
+	if round.P1 != "" && round.P2 != "" {
+		result, err := g.rules.Resolve(round.P1, round.P2)
+		if err != nil {
+			return MoveResult{}, err
+		}
+		if err := g.finishRound(round.P1, round.P2, result); err != nil {
+			return MoveResult{}, err
+		}
+	}
+	return g.moveResult(round.Number), nil
+}
+
+// startRevealPhase moves the round on to reveals. Reveals always have a
+// deadline, DefaultRevealTimeout when the game has no move timeout, since a
+// player who never reveals would otherwise hold the round up forever.
+func (g *Game) startRevealPhase() {
+	round := g.CurrentRound
+	round.Phase = PhaseReveal
+	timeout := g.Config.MoveTimeout
+	if timeout <= 0 {
+		timeout = DefaultRevealTimeout
+	}
+	round.Deadline = g.clock.Now().Add(timeout)
+	g.emit(RevealPhaseStarted{EventMeta: g.meta(), Round: *round})
+}
+
+// expireCommitPhase plays a random move for every player who failed to
+// commit. Players who did commit still have to reveal, so the round only
+// resolves here when nobody committed at all.
+func (g *Game) expireCommitPhase() error {
+	round := g.CurrentRound
+	now := g.clock.Now()
+
+	if round.P1Commit == "" {
+		round.P1 = g.randomMove()
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player: g.P1,
+			Action: ActionTimeout,
+			Move:   round.P1,
+			At:     now,
+		})
+	}
+	if round.P2Commit == "" {
+		round.P2 = g.randomMove()
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player: g.P2,
+			Action: ActionTimeout,
+			Move:   round.P2,
+			At:     now,
+		})
+	}
+
+	if round.P1Commit == "" && round.P2Commit == "" {
+		result, err := g.rules.Resolve(round.P1, round.P2)
+		if err != nil {
+			return err
+		}
+		return g.finishRound(round.P1, round.P2, result)
+	}
+
+	round.TimedOut = false
+	g.startRevealPhase()
+	return nil
+}
+
+func (g *Game) recordMissingReveals() {
+	round := g.CurrentRound
+	now := g.clock.Now()
+
+	if round.P1Commit != "" && round.P1 == "" {
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player: g.P1,
+			Action: ActionTimeout,
+			At:     now,
+		})
+	}
+	if round.P2Commit != "" && round.P2 == "" {
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player: g.P2,
+			Action: ActionTimeout,
+			At:     now,
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func newCommitRevealGame(policy TimeoutPolicy) (*Game, *MockClock) {
+	clock := NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+	config := DefaultMatchConfig()
+	config.CommitReveal = true
+	config.MoveTimeout = 10 * time.Second
+	config.TimeoutPolicy = policy
+	return newGame("game1", "Alice", "Bob", config, clock), clock
+}
+
+func TestHashMove(t *testing.T) {
+	if HashMove(Rock, "abc") != HashMove(Rock, "abc") {
+		t.Errorf("Expected hashing to be deterministic")
+	}
+	if HashMove(Rock, "abc") == HashMove(Paper, "abc") {
+		t.Errorf("Expected different moves to hash differently")
+	}
+	if HashMove(Rock, "abc") == HashMove(Rock, "abd") {
+		t.Errorf("Expected different nonces to hash differently")
+	}
+}
+
+func TestCommitReveal(t *testing.T) {
+	t.Run("Round starts in commit phase", func(t *testing.T) {
+		game, _ := newCommitRevealGame(TimeoutForfeit)
+		round, err := game.NewRound()
+		if err != nil {
+			t.Fatalf("Expected no error creating new round, got %v", err)
+		}
+		if round.Phase != PhaseCommit {
+			t.Errorf("Expected commit phase, got %s", round.Phase)
+		}
+	})
+
+	t.Run("Honest players resolve the round", func(t *testing.T) {
+		game, _ := newCommitRevealGame(TimeoutForfeit)
+		game.NewRound()
+
+		if _, err := game.CommitMove("Alice", HashMove(Rock, "n1")); err != nil {
+			t.Fatalf("Expected no error committing, got %v", err)
+		}
+		if game.CurrentRound.Phase != PhaseCommit {
+			t.Fatalf("Expected to stay in commit phase, got %s", game.CurrentRound.Phase)
+		}
+		if _, err := game.CommitMove("Bob", HashMove(Scissors, "n2")); err != nil {
+			t.Fatalf("Expected no error committing, got %v", err)
+		}
+		if game.CurrentRound.Phase != PhaseReveal {
+			t.Fatalf("Expected reveal phase, got %s", game.CurrentRound.Phase)
+		}
+
+		if _, err := game.RevealMove("Alice", Rock, "n1"); err != nil {
+			t.Fatalf("Expected no error revealing, got %v", err)
+		}
+		if _, err := game.RevealMove("Bob", Scissors, "n2"); err != nil {
+			t.Fatalf("Expected no error revealing, got %v", err)
+		}
+
+		if game.CurrentRound != nil || len(game.Rounds) != 1 {
+			t.Fatalf("Expected round to be resolved")
+		}
+		played := game.Rounds[0]
+		if played.Winner != "p1" {
+			t.Errorf("Expected P1 to win, got %s", played.Winner)
+		}
+		if len(played.Transcript) != 4 {
+			t.Errorf("Expected 4 transcript entries, got %d", len(played.Transcript))
+		}
+	})
+
+	t.Run("Mismatched reveal forfeits the round", func(t *testing.T) {
+		game, _ := newCommitRevealGame(TimeoutForfeit)
+		game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		game.CommitMove("Bob", HashMove(Scissors, "n2"))
+
+		if _, err := game.RevealMove("Alice", Paper, "n1"); err != nil {
+			t.Fatalf("Expected no error revealing, got %v", err)
+		}
+		if len(game.Rounds) != 1 || game.Rounds[0].Winner != "p2" {
+			t.Fatalf("Expected P2 to win by forfeit")
+		}
+		last := game.Rounds[0].Transcript[len(game.Rounds[0].Transcript)-1]
+		if last.Valid || last.Move != Paper {
+			t.Errorf("Expected invalid reveal of paper in transcript, got %+v", last)
+		}
+	})
+
+	t.Run("Cannot commit twice", func(t *testing.T) {
+		game, _ := newCommitRevealGame(TimeoutForfeit)
+		game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		if _, err := game.CommitMove("Alice", HashMove(Paper, "n1")); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Cannot reveal during commit phase", func(t *testing.T) {
+		game, _ := newCommitRevealGame(TimeoutForfeit)
+		game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		if _, err := game.RevealMove("Alice", Rock, "n1"); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Rejected without commit-reveal", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		if _, err := game.CommitMove("Alice", HashMove(Rock, "n1")); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Missing reveal forfeits the round", func(t *testing.T) {
+		game, clock := newCommitRevealGame(TimeoutRandom)
+		round, _ := game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		clock.Advance(5 * time.Second)
+		game.CommitMove("Bob", HashMove(Scissors, "n2"))
+		game.RevealMove("Bob", Scissors, "n2")
+
+		clock.Advance(5 * time.Second)
+		if expired, _ := game.ExpireRound(round.Number); expired {
+			t.Fatalf("Expected reveal phase to get a fresh deadline")
+		}
+
+		clock.Advance(5 * time.Second)
+		expired, err := game.ExpireRound(round.Number)
+		if err != nil || !expired {
+			t.Fatalf("Expected round to expire, got %v, %v", expired, err)
+		}
+		if game.Rounds[0].Winner != "p2" {
+			t.Errorf("Expected P2 to win by forfeit, got %s", game.Rounds[0].Winner)
+		}
+	})
+
+	t.Run("Missing reveal forfeits without a move timeout", func(t *testing.T) {
+		game, clock := newCommitRevealGame(TimeoutForfeit)
+		game.Config.MoveTimeout = 0
+		round, _ := game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		game.CommitMove("Bob", HashMove(Scissors, "n2"))
+		game.RevealMove("Bob", Scissors, "n2")
+
+		if want := clock.Now().Add(DefaultRevealTimeout); !game.CurrentRound.Deadline.Equal(want) {
+			t.Fatalf("Expected the reveal deadline to be %v, got %v", want, game.CurrentRound.Deadline)
+		}
+		clock.Advance(DefaultRevealTimeout)
+		expired, err := game.ExpireRound(round.Number)
+		if err != nil || !expired {
+			t.Fatalf("Expected round to expire, got %v, %v", expired, err)
+		}
+		if game.Rounds[0].Winner != "p2" {
+			t.Errorf("Expected P2 to win by forfeit, got %s", game.Rounds[0].Winner)
+		}
+	})
+
+	t.Run("Random policy fills a missing commit", func(t *testing.T) {
+		game, clock := newCommitRevealGame(TimeoutRandom)
+		round, _ := game.NewRound()
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+
+		clock.Advance(10 * time.Second)
+		expired, err := game.ExpireRound(round.Number)
+		if err != nil || !expired {
+			t.Fatalf("Expected commit phase to expire, got %v, %v", expired, err)
+		}
+		if game.CurrentRound == nil || game.CurrentRound.Phase != PhaseReveal {
+			t.Fatalf("Expected reveal phase")
+		}
+		if !game.CurrentRound.P2.IsValidMove() {
+			t.Errorf("Expected a random move for P2, got %s", game.CurrentRound.P2)
+		}
+
+		if _, err := game.RevealMove("Alice", Rock, "n1"); err != nil {
+			t.Fatalf("Expected no error revealing, got %v", err)
+		}
+		if game.CurrentRound != nil {
+			t.Errorf("Expected round to be resolved")
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MatchFormat string
+
+const (
+	FormatBestOf  MatchFormat = "best_of"
+	FormatFirstTo MatchFormat = "first_to"
+)
+
+type TimeoutPolicy string
+
+const (
+	// TimeoutForfeit gives the round to the player who moved in time, or
+	// draws it when neither did.
+	TimeoutForfeit TimeoutPolicy = "forfeit"
+	// TimeoutRandom plays a random move for every player who did not move.
+	TimeoutRandom TimeoutPolicy = "random"
+	// TimeoutAbort ends the whole game without a winner.
+	TimeoutAbort TimeoutPolicy = "abort"
+)
+
+type MatchConfig struct {
+	Format MatchFormat `json:"format"`
+	// Rounds is N for best-of-N and K for first-to-K.
+	Rounds int `json:"rounds"`
+	// DrawsCount makes drawn rounds consume one of the N rounds of a best-of-N.
+	DrawsCount bool `json:"drawsCount"`
+	// SuddenDeath keeps playing past the round limit while the score is tied.
+	SuddenDeath bool `json:"suddenDeath"`
+	// MaxRounds is a hard cap on total rounds played, draws included. Zero
+	// means no cap.
+	MaxRounds int `json:"maxRounds"`
+	// RuleSet names a registered rule set. Empty means the classic rules.
+	RuleSet string `json:"ruleSet"`
+	// MoveTimeout is how long players have to move once a round starts. Zero
+	// means rounds never time out. It is encoded in seconds, the unit clients
+	// send it in.
+	MoveTimeout   time.Duration `json:"moveTimeout"`
+	TimeoutPolicy TimeoutPolicy `json:"timeoutPolicy"`
+	// CommitReveal makes players commit to a hash of their move before
+	// revealing it, so neither side can react to the other's move.
+	CommitReveal bool `json:"commitReveal"`
+	// Ranked games update the players' ratings when they finish.
+	Ranked bool `json:"ranked"`
+	// VsBot marks games against a server-side bot. They are never ranked.
+	VsBot bool `json:"vsBot"`
+}
+
+func DefaultMatchConfig() MatchConfig {
+	return MatchConfig{
+		Format:        FormatBestOf,
+		Rounds:        3,
+		DrawsCount:    true,
+		RuleSet:       Classic.Name,
+		TimeoutPolicy: TimeoutForfeit,
+	}
+}
+
+// matchConfigFields has the fields of MatchConfig without its JSON methods.
+type matchConfigFields MatchConfig
+
+// matchConfigJSON is the encoded form of MatchConfig. Its MoveTimeout
+// shadows the embedded one.
+type matchConfigJSON struct {
+	matchConfigFields
+	MoveTimeout float64 `json:"moveTimeout"`
+}
+
+func (c MatchConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(matchConfigJSON{
+		matchConfigFields: matchConfigFields(c),
+		MoveTimeout:       c.MoveTimeout.Seconds(),
+	})
+}
+
+func (c *MatchConfig) UnmarshalJSON(data []byte) error {
+	encoded := matchConfigJSON{
+		matchConfigFields: matchConfigFields(*c),
+		MoveTimeout:       c.MoveTimeout.Seconds(),
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	*c = MatchConfig(encoded.matchConfigFields)
+	c.MoveTimeout = time.Duration(encoded.MoveTimeout * float64(time.Second))
+	return nil
+}
+
+// ParseMatchFormat parses formats like "best_of_5" or "first_to_3" into a
+// config with the default draw and tiebreak settings.
+func ParseMatchFormat(s string) (MatchConfig, error) {
+	config := DefaultMatchConfig()
+
+	for _, format := range []MatchFormat{FormatBestOf, FormatFirstTo} {
+		rest, ok := strings.CutPrefix(s, string(format)+"_")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return MatchConfig{}, fmt.Errorf("invalid match format %q", s)
+		}
+
+		config.Format = format
+		config.Rounds = n
+		return config, config.Validate()
+	}
+
+	return MatchConfig{}, fmt.Errorf("invalid match format %q", s)
+}
+
+func (c MatchConfig) Validate() error {
+	switch c.Format {
+	case FormatBestOf, FormatFirstTo:
+	default:
+		return fmt.Errorf("unknown match format %q", c.Format)
+	}
+
+	if c.Rounds < 1 {
+		return errors.New("rounds must be at least 1")
+	}
+	if c.MaxRounds < 0 {
+		return errors.New("max rounds cannot be negative")
+	}
+	if c.MoveTimeout < 0 {
+		return errors.New("move timeout cannot be negative")
+	}
+	switch c.TimeoutPolicy {
+	case "", TimeoutForfeit, TimeoutRandom, TimeoutAbort:
+	default:
+		return fmt.Errorf("unknown timeout policy %q", c.TimeoutPolicy)
+	}
+	if c.RuleSet != "" {
+		if _, exists := GetRuleSet(c.RuleSet); !exists {
+			return fmt.Errorf("unknown rule set %q", c.RuleSet)
+		}
+	}
+	if c.Ranked && c.VsBot {
+		return errors.New("bot games cannot be ranked")
+	}
+
+	return nil
+}
+
+// WinsNeeded is the number of round wins that clinches the match.
+func (c MatchConfig) WinsNeeded() int {
+	if c.Format == FormatFirstTo {
+		return c.Rounds
+	}
+	return c.Rounds/2 + 1
+}
+
+// Rules returns the rule set named by the config, falling back to the
+// classic rules when it is empty or unknown.
+func (c MatchConfig) Rules() *RuleSet {
+	if rs, exists := GetRuleSet(c.RuleSet); exists {
+		return rs
+	}
+	return Classic
+}
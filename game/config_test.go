@@ -0,0 +1,172 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func playRounds(t *testing.T, game *Game, moves [][2]Move) {
+	t.Helper()
+	for i, m := range moves {
+		if _, err := game.NewRound(); err != nil {
+			t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
+		}
+		if err := game.PlayRound(m[0], m[1]); err != nil {
+			t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
+		}
+	}
+}
+
+func TestParseMatchFormat(t *testing.T) {
+	tests := []struct {
+		input       string
+		format      MatchFormat
+		rounds      int
+		expectError bool
+	}{
+		{"best_of_3", FormatBestOf, 3, false},
+		{"best_of_5", FormatBestOf, 5, false},
+		{"first_to_3", FormatFirstTo, 3, false},
+		{"best_of_0", "", 0, true},
+		{"best_of_x", "", 0, true},
+		{"bo3", "", 0, true},
+		{"", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("Parse format: "+tt.input, func(t *testing.T) {
+			config, err := ParseMatchFormat(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for input %s, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for input %s, got %v", tt.input, err)
+			}
+			if config.Format != tt.format || config.Rounds != tt.rounds {
+				t.Errorf("Expected %s/%d, got %s/%d", tt.format, tt.rounds, config.Format, config.Rounds)
+			}
+		})
+	}
+}
+
+func TestMatchConfigValidate(t *testing.T) {
+	t.Run("Default config is valid", func(t *testing.T) {
+		if err := DefaultMatchConfig().Validate(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		config := DefaultMatchConfig()
+		config.Format = "round_robin"
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Negative round cap", func(t *testing.T) {
+		config := DefaultMatchConfig()
+		config.MaxRounds = -1
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Ranked bot game", func(t *testing.T) {
+		config := DefaultMatchConfig()
+		config.Ranked = true
+		config.VsBot = true
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestMatchFormats(t *testing.T) {
+	t.Run("Best of 5 ends after 3 wins", func(t *testing.T) {
+		config := MatchConfig{Format: FormatBestOf, Rounds: 5, DrawsCount: true}
+		game := NewGameWithConfig("game1", "Alice", "Bob", config)
+		playRounds(t, game, [][2]Move{{Rock, Scissors}, {Rock, Paper}, {Rock, Scissors}, {Rock, Scissors}})
+		if game.Winner != "Alice" || game.EndReason != EndReasonMajority {
+			t.Errorf("Expected 'Alice' to win by majority, got '%s' by '%s'", game.Winner, game.EndReason)
+		}
+	})
+
+	t.Run("Draws do not count toward the limit", func(t *testing.T) {
+		config := MatchConfig{Format: FormatBestOf, Rounds: 3}
+		game := NewGameWithConfig("game1", "Alice", "Bob", config)
+		playRounds(t, game, [][2]Move{{Rock, Rock}, {Paper, Paper}, {Scissors, Scissors}, {Rock, Scissors}})
+		if game.IsOver() {
+			t.Fatalf("Expected game to continue, got over with reason '%s'", game.EndReason)
+		}
+		playRounds(t, game, [][2]Move{{Rock, Scissors}})
+		if game.Winner != "Alice" {
+			t.Errorf("Expected 'Alice' to win, got '%s'", game.Winner)
+		}
+	})
+
+	t.Run("First to 2 ignores draws", func(t *testing.T) {
+		config := MatchConfig{Format: FormatFirstTo, Rounds: 2}
+		game := NewGameWithConfig("game1", "Alice", "Bob", config)
+		playRounds(t, game, [][2]Move{{Rock, Rock}, {Rock, Paper}, {Rock, Rock}, {Rock, Rock}})
+		if game.IsOver() {
+			t.Fatalf("Expected game to continue, got over with reason '%s'", game.EndReason)
+		}
+		playRounds(t, game, [][2]Move{{Scissors, Paper}, {Paper, Scissors}})
+		if game.Winner != "Bob" || game.EndReason != EndReasonMajority {
+			t.Errorf("Expected 'Bob' to win by majority, got '%s' by '%s'", game.Winner, game.EndReason)
+		}
+	})
+
+	t.Run("Sudden death after a tied best of 3", func(t *testing.T) {
+		config := MatchConfig{Format: FormatBestOf, Rounds: 3, DrawsCount: true, SuddenDeath: true}
+		game := NewGameWithConfig("game1", "Alice", "Bob", config)
+		playRounds(t, game, [][2]Move{{Rock, Scissors}, {Rock, Paper}, {Rock, Rock}})
+		if game.IsOver() {
+			t.Fatalf("Expected sudden death, got over with reason '%s'", game.EndReason)
+		}
+		playRounds(t, game, [][2]Move{{Paper, Paper}, {Paper, Scissors}})
+		if game.Winner != "Bob" || game.EndReason != EndReasonSuddenDeath {
+			t.Errorf("Expected 'Bob' to win by sudden death, got '%s' by '%s'", game.Winner, game.EndReason)
+		}
+	})
+
+	t.Run("Round cap ends a first to match", func(t *testing.T) {
+		config := MatchConfig{Format: FormatFirstTo, Rounds: 5, MaxRounds: 3}
+		game := NewGameWithConfig("game1", "Alice", "Bob", config)
+		playRounds(t, game, [][2]Move{{Rock, Scissors}, {Rock, Rock}, {Rock, Rock}})
+		if game.Winner != "Alice" || game.EndReason != EndReasonRoundCap {
+			t.Errorf("Expected 'Alice' to win by round cap, got '%s' by '%s'", game.Winner, game.EndReason)
+		}
+		if _, err := game.NewRound(); err == nil {
+			t.Errorf("Expected error creating round past the cap, got nil")
+		}
+	})
+}
+
+func TestMatchConfigJSON(t *testing.T) {
+	config := DefaultMatchConfig()
+	config.MoveTimeout = 1500 * time.Millisecond
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	if fields["moveTimeout"] != 1.5 {
+		t.Errorf("Expected the move timeout in seconds, got %v", fields["moveTimeout"])
+	}
+
+	var decoded MatchConfig
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded != config {
+		t.Errorf("Expected %+v to round trip, got %+v", config, decoded)
+	}
+}
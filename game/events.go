@@ -0,0 +1,305 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventGameCreated        EventType = "game_created"
+	EventPlayerJoined       EventType = "player_joined"
+	EventGameReady          EventType = "game_ready"
+	EventPlayerConnected    EventType = "player_connected"
+	EventPlayerDisconnected EventType = "player_disconnected"
+	EventRoundStarted       EventType = "round_started"
+	EventMoveSubmitted      EventType = "move_submitted"
+	EventMoveCommitted      EventType = "move_committed"
+	EventRevealPhaseStarted EventType = "reveal_phase_started"
+	EventMoveRevealed       EventType = "move_revealed"
+	EventRoundResolved      EventType = "round_resolved"
+	EventGameFinished       EventType = "game_finished"
+)
+
+// Event is a domain event emitted by a game. Subscribers tell the concrete
+// events apart with a type switch.
+type Event interface {
+	Type() EventType
+	GameID() string
+	OccurredAt() time.Time
+}
+
+type EventMeta struct {
+	Game string    `json:"gameID"`
+	At   time.Time `json:"at"`
+}
+
+func (m EventMeta) GameID() string {
+	return m.Game
+}
+
+func (m EventMeta) OccurredAt() time.Time {
+	return m.At
+}
+
+type GameCreated struct {
+	EventMeta
+	P1     string      `json:"p1"`
+	P2     string      `json:"p2"`
+	Config MatchConfig `json:"config"`
+}
+
+type PlayerJoined struct {
+	EventMeta
+	PlayerID string `json:"playerID"`
+	Seat     Seat   `json:"seat"`
+}
+
+type GameReady struct {
+	EventMeta
+	P1 string `json:"p1"`
+	P2 string `json:"p2"`
+}
+
+type PlayerConnected struct {
+	EventMeta
+	PlayerID string `json:"playerID"`
+}
+
+type PlayerDisconnected struct {
+	EventMeta
+	PlayerID string `json:"playerID"`
+}
+
+type RoundStarted struct {
+	EventMeta
+	Round Round `json:"round"`
+}
+
+type MoveSubmitted struct {
+	EventMeta
+	PlayerID string `json:"playerID"`
+	Round    int    `json:"round"`
+	Move     Move   `json:"move"`
+}
+
+type MoveCommitted struct {
+	EventMeta
+	PlayerID   string `json:"playerID"`
+	Round      int    `json:"round"`
+	Commitment string `json:"commitment"`
+}
+
+type RevealPhaseStarted struct {
+	EventMeta
+	Round Round `json:"round"`
+}
+
+type MoveRevealed struct {
+	EventMeta
+	PlayerID string `json:"playerID"`
+	Round    int    `json:"round"`
+	Move     Move   `json:"move"`
+	Nonce    string `json:"nonce"`
+	Valid    bool   `json:"valid"`
+}
+
+type RoundResolved struct {
+	EventMeta
+	Round Round `json:"round"`
+}
+
+type GameFinished struct {
+	EventMeta
+	Winner string `json:"winner"`
+	Reason string `json:"reason"`
+	P1Wins int    `json:"p1Wins"`
+	P2Wins int    `json:"p2Wins"`
+}
+
+func (GameCreated) Type() EventType        { return EventGameCreated }
+func (PlayerJoined) Type() EventType       { return EventPlayerJoined }
+func (GameReady) Type() EventType          { return EventGameReady }
+func (PlayerConnected) Type() EventType    { return EventPlayerConnected }
+func (PlayerDisconnected) Type() EventType { return EventPlayerDisconnected }
+func (RoundStarted) Type() EventType       { return EventRoundStarted }
+func (MoveSubmitted) Type() EventType      { return EventMoveSubmitted }
+func (MoveCommitted) Type() EventType      { return EventMoveCommitted }
+func (RevealPhaseStarted) Type() EventType { return EventRevealPhaseStarted }
+func (MoveRevealed) Type() EventType       { return EventMoveRevealed }
+func (RoundResolved) Type() EventType      { return EventRoundResolved }
+func (GameFinished) Type() EventType       { return EventGameFinished }
+
+type EventHandler func(Event)
+
+type EventBus interface {
+	Publish(event Event)
+	Subscribe(handler EventHandler) (unsubscribe func())
+}
+
+// InMemoryEventBus delivers every event synchronously to each subscriber, in
+// the order they subscribed.
+type InMemoryEventBus struct {
+	handlers []subscription
+	nextID   int
+	mu       sync.RWMutex
+}
+
+type subscription struct {
+	id      int
+	handler EventHandler
+}
+
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{}
+}
+
+func (b *InMemoryEventBus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := make([]EventHandler, len(b.handlers))
+	for i, sub := range b.handlers {
+		handlers[i] = sub.handler
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+func (b *InMemoryEventBus) Subscribe(handler EventHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers = append(b.handlers, subscription{id: id, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for i, sub := range b.handlers {
+			if sub.id == id {
+				b.handlers = append(b.handlers[:i:i], b.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit appends an event to the game's log and queues it to be published once
+// the game's mutex is released. Must be called with the mutex held.
+func (g *Game) emit(event Event) {
+	g.log = append(g.log, event)
+	if g.bus == nil {
+		return
+	}
+	g.pending = append(g.pending, event)
+}
+
+func (g *Game) meta() EventMeta {
+	return g.metaAt(g.clock.Now())
+}
+
+// metaAt stamps an event with the time the game recorded for the change, so
+// replaying the event restores the same timestamps.
+func (g *Game) metaAt(at time.Time) EventMeta {
+	return EventMeta{Game: g.ID, At: at}
+}
+
+// flush publishes the queued events. It must be called without holding the
+// game's mutex so subscribers can read the game; publishMu keeps events in
+// the order they were emitted. Subscribers must not mutate the game from
+// inside the handler.
+func (g *Game) flush() {
+	if g.bus == nil {
+		return
+	}
+
+	g.publishMu.Lock()
+	defer g.publishMu.Unlock()
+
+	g.mu.Lock()
+	events := g.pending
+	g.pending = nil
+	g.mu.Unlock()
+
+	for _, event := range events {
+		g.bus.Publish(event)
+	}
+}
+
+// EventLog returns a copy of every event the game has emitted, oldest first.
+func (g *Game) EventLog() []Event {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return slices.Clone(g.log)
+}
+
+// MarshalEvent encodes an event as a JSON object holding the event's fields
+// and its type under "type".
+func MarshalEvent(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(event.Type())
+	return json.Marshal(fields)
+}
+
+// UnmarshalEvent decodes an event encoded by MarshalEvent.
+func UnmarshalEvent(data []byte) (Event, error) {
+	var envelope struct {
+		Type EventType `json:"type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	switch envelope.Type {
+	case EventGameCreated:
+		return decodeEvent[GameCreated](data)
+	case EventPlayerJoined:
+		return decodeEvent[PlayerJoined](data)
+	case EventGameReady:
+		return decodeEvent[GameReady](data)
+	case EventPlayerConnected:
+		return decodeEvent[PlayerConnected](data)
+	case EventPlayerDisconnected:
+		return decodeEvent[PlayerDisconnected](data)
+	case EventRoundStarted:
+		return decodeEvent[RoundStarted](data)
+	case EventMoveSubmitted:
+		return decodeEvent[MoveSubmitted](data)
+	case EventMoveCommitted:
+		return decodeEvent[MoveCommitted](data)
+	case EventRevealPhaseStarted:
+		return decodeEvent[RevealPhaseStarted](data)
+	case EventMoveRevealed:
+		return decodeEvent[MoveRevealed](data)
+	case EventRoundResolved:
+		return decodeEvent[RoundResolved](data)
+	case EventGameFinished:
+		return decodeEvent[GameFinished](data)
+	}
+	return nil, fmt.Errorf("unknown event type %q", envelope.Type)
+}
+
+func decodeEvent[T Event](data []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
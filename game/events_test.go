@@ -0,0 +1,140 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func collectEvents(bus EventBus) *[]Event {
+	var events []Event
+	bus.Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+	return &events
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type()
+	}
+	return types
+}
+
+func TestInMemoryEventBus(t *testing.T) {
+	t.Run("Delivers to subscribers in order", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		var order []int
+		bus.Subscribe(func(Event) { order = append(order, 1) })
+		bus.Subscribe(func(Event) { order = append(order, 2) })
+
+		bus.Publish(PlayerConnected{PlayerID: "Alice"})
+		if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+			t.Errorf("Expected handlers [1 2], got %v", order)
+		}
+	})
+
+	t.Run("Unsubscribe stops delivery", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		count := 0
+		unsubscribe := bus.Subscribe(func(Event) { count++ })
+		bus.Publish(PlayerConnected{PlayerID: "Alice"})
+		unsubscribe()
+		bus.Publish(PlayerConnected{PlayerID: "Alice"})
+		if count != 1 {
+			t.Errorf("Expected 1 delivery, got %d", count)
+		}
+	})
+}
+
+func TestGameEvents(t *testing.T) {
+	t.Run("Full game emits events in order", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		events := collectEvents(bus)
+		m := NewManager(WithEventBus(bus))
+
+		game, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		game.SetPlayerConnected("Alice", true)
+		for i := 0; i < 2; i++ {
+			game.NewRound()
+			game.SubmitMove("Alice", Rock)
+			game.SubmitMove("Bob", Scissors)
+		}
+		game.SetPlayerConnected("Alice", false)
+
+		expected := []EventType{
+			EventGameCreated,
+			EventPlayerConnected,
+			EventRoundStarted, EventMoveSubmitted, EventMoveSubmitted, EventRoundResolved,
+			EventRoundStarted, EventMoveSubmitted, EventMoveSubmitted, EventRoundResolved,
+			EventGameFinished,
+			EventPlayerDisconnected,
+		}
+		got := eventTypes(*events)
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, got)
+			}
+		}
+
+		for _, e := range *events {
+			if e.GameID() != game.ID {
+				t.Errorf("Expected event for game %s, got %s", game.ID, e.GameID())
+			}
+		}
+		finished := (*events)[10].(GameFinished)
+		if finished.Winner != "Alice" || finished.Reason != EndReasonMajority || finished.P1Wins != 2 {
+			t.Errorf("Expected 'Alice' to win 2-0 by majority, got %+v", finished)
+		}
+	})
+
+	t.Run("Subscribers can read the game", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		m := NewManager(WithEventBus(bus))
+		var states []State
+		bus.Subscribe(func(e Event) {
+			if game, exists := m.GetGame(e.GameID()); exists {
+				states = append(states, game.SpectatorView().State)
+			}
+		})
+
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.NewRound()
+		if len(states) != 2 || states[1] != StateRoundInProgress {
+			t.Errorf("Expected to observe the round in progress, got %v", states)
+		}
+	})
+
+	t.Run("Timed out round is resolved", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		events := collectEvents(bus)
+		clock := NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+		m := NewManager(WithEventBus(bus), WithClock(clock))
+
+		config := DefaultMatchConfig()
+		config.MoveTimeout = time.Second
+		game, _ := m.CreateGame("Alice", "Bob", config)
+		round, _ := game.NewRound()
+		clock.Advance(time.Second)
+		game.ExpireRound(round.Number)
+
+		last := (*events)[len(*events)-1].(RoundResolved)
+		if !last.Round.TimedOut || last.Round.Winner != "draw" {
+			t.Errorf("Expected a timed out draw, got %+v", last.Round)
+		}
+	})
+
+	t.Run("Games without a bus do not queue events", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		if len(game.pending) != 0 {
+			t.Errorf("Expected no pending events, got %d", len(game.pending))
+		}
+	})
+}
@@ -2,44 +2,105 @@ package game
 
 import (
 	"errors"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
 
-const MaxRounds = 3
+const (
+	EndReasonMajority    = "majority"
+	EndReasonMaxRounds   = "max_rounds"
+	EndReasonSuddenDeath = "sudden_death"
+	EndReasonRoundCap    = "round_cap"
+	EndReasonAborted     = "aborted"
+	EndReasonAbandoned   = "abandoned"
+)
 
 type Game struct {
 	ID string
 	P1 string
 	P2 string
 
+	State  State
+	Config MatchConfig
+	rules  *RuleSet
+
 	Rounds       []Round
 	CurrentRound *Round
 
-	P1Wins int
-	P2Wins int
-	Winner string
+	P1Wins    int
+	P2Wins    int
+	Winner    string
+	EndReason string
 
 	CreatedAt    time.Time
 	LastActivity time.Time
 
 	P1Connected bool
 	P2Connected bool
+	clock       Clock
+	bus         EventBus
+	pending     []Event
+	log         []Event
 	mu          sync.Mutex
+	publishMu   sync.Mutex
+}
+
+// MoveResult describes what a move did to the game. Round is the round the
+// move was made in, after the move; when Resolved is set it is the finished
+// round as stored in Rounds.
+type MoveResult struct {
+	Round    Round
+	Resolved bool
+	GameOver bool
+}
+
+type GameResult struct {
+	Winner string         `json:"winner"`
+	Reason string         `json:"reason"`
+	P1Wins int            `json:"p1Wins"`
+	P2Wins int            `json:"p2Wins"`
+	Rounds []RoundSummary `json:"rounds"`
 }
 
 func NewGame(id, p1, p2 string) *Game {
+	return NewGameWithConfig(id, p1, p2, DefaultMatchConfig())
+}
+
+func NewGameWithConfig(id, p1, p2 string, config MatchConfig) *Game {
+	g := newGame(id, p1, p2, config, RealClock{})
+	g.emitCreated()
+	return g
+}
+
+func newGame(id, p1, p2 string, config MatchConfig, clock Clock) *Game {
+	now := clock.Now()
+
+	state := StateReady
+	if p2 == "" {
+		state = StateWaitingForOpponent
+	}
+
 	return &Game{
 		ID:           id,
 		P1:           p1,
 		P2:           p2,
+		State:        state,
+		Config:       config,
+		rules:        config.Rules(),
 		Rounds:       []Round{},
-		CreatedAt:    time.Now(),
-		LastActivity: time.Now(),
+		CreatedAt:    now,
+		LastActivity: now,
+		clock:        clock,
 	}
 }
 
+func (g *Game) emitCreated() {
+	g.emit(GameCreated{EventMeta: g.metaAt(g.CreatedAt), P1: g.P1, P2: g.P2, Config: g.Config})
+}
+
 func (g *Game) SetPlayerConnected(player string, connected bool) {
+	defer g.flush()
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
@@ -48,8 +109,60 @@ func (g *Game) SetPlayerConnected(player string, connected bool) {
 		g.P1Connected = connected
 	case g.P2:
 		g.P2Connected = connected
+	default:
+		return
+	}
+	g.LastActivity = g.clock.Now()
+
+	if connected {
+		g.emit(PlayerConnected{EventMeta: g.metaAt(g.LastActivity), PlayerID: player})
+	} else {
+		g.emit(PlayerDisconnected{EventMeta: g.metaAt(g.LastActivity), PlayerID: player})
 	}
-	g.LastActivity = time.Now()
+}
+
+// Abandon ends the game as a forfeit by a player who left it, making their
+// opponent the winner. When the opponent is gone too, nobody remains to win
+// and the game is aborted instead. It reports false without changing
+// anything when the player has connected again, has no opponent yet or the
+// game is already over, so a stale grace period timer firing late is
+// harmless.
+func (g *Game) Abandon(player string) (bool, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var connected, opponentConnected bool
+	var opponent string
+	switch player {
+	case g.P1:
+		connected, opponent, opponentConnected = g.P1Connected, g.P2, g.P2Connected
+	case g.P2:
+		connected, opponent, opponentConnected = g.P2Connected, g.P1, g.P1Connected
+	default:
+		return false, errors.New("not a player in this game")
+	}
+	if connected || opponent == "" || g.isOver() {
+		return false, nil
+	}
+
+	if opponentConnected {
+		if err := g.transition(StateFinished); err != nil {
+			return false, err
+		}
+		g.Winner = opponent
+		g.EndReason = EndReasonAbandoned
+	} else {
+		if err := g.transition(StateAbandoned); err != nil {
+			return false, err
+		}
+		g.EndReason = EndReasonAborted
+	}
+
+	// A round in progress is dropped rather than scored.
+	g.CurrentRound = nil
+	g.emitFinished()
+	return true, nil
 }
 
 func (g *Game) IsActive() bool {
@@ -59,35 +172,251 @@ func (g *Game) IsActive() bool {
 	return g.P1Connected || g.P2Connected
 }
 
+func (g *Game) Rules() *RuleSet {
+	return g.rules
+}
+
+func (g *Game) IsOver() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.isOver()
+}
+
+func (g *Game) isOver() bool {
+	return g.State.IsTerminal() || g.Winner != ""
+}
+
+func (g *Game) lastActivity() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.LastActivity
+}
+
+// NewRound starts the next round and returns a copy of it.
 func (g *Game) NewRound() (*Round, error) {
-	if g.Winner != "" {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.isOver() {
 		return nil, errors.New("game over")
-	} else if len(g.Rounds) >= MaxRounds {
+	} else if g.roundsExhausted() {
 		return nil, errors.New("maximum rounds reached")
 	}
 
-	newRound := Round{}
+	now := g.clock.Now()
+	newRound := Round{
+		Number:    len(g.Rounds) + 1,
+		Phase:     PhaseMove,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if g.Config.CommitReveal {
+		newRound.Phase = PhaseCommit
+	}
+	if g.Config.MoveTimeout > 0 {
+		newRound.Deadline = now.Add(g.Config.MoveTimeout)
+	}
+
+	if err := g.transition(StateRoundInProgress); err != nil {
+		return nil, err
+	}
 	g.CurrentRound = &newRound
-	return &newRound, nil
+	g.emit(RoundStarted{EventMeta: g.metaAt(now), Round: newRound})
+
+	started := newRound
+	return &started, nil
 }
 
 func (g *Game) PlayRound(p1Move, p2Move Move) error {
-	if g.Winner != "" {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.isOver() {
 		return errors.New("game over")
-	} else if len(g.Rounds) >= MaxRounds {
+	} else if g.roundsExhausted() {
 		return errors.New("maximum rounds reached")
 	} else if g.CurrentRound == nil {
 		return errors.New("no current round to play")
 	}
 
-	result, err := ResolveRound(p1Move, p2Move)
+	result, err := g.rules.Resolve(p1Move, p2Move)
 	if err != nil {
 		return err
 	}
 
+	return g.finishRound(p1Move, p2Move, result)
+}
+
+// SubmitMove records a player's move in the current round and resolves the
+// round once both players have moved.
+func (g *Game) SubmitMove(player string, move Move) (MoveResult, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	round := g.CurrentRound
+	if g.isOver() {
+		return MoveResult{}, errors.New("game over")
+	} else if round == nil {
+		return MoveResult{}, errors.New("no active round")
+	} else if round.Phase != PhaseMove {
+		return MoveResult{}, errors.New("game uses commit-reveal, send commit_move instead")
+	} else if !g.rules.IsValidMove(move) {
+		return MoveResult{}, errors.New("invalid move")
+	}
+
+	switch player {
+	case g.P1:
+		if round.P1 != "" {
+			return MoveResult{}, errors.New("move already submitted")
+		}
+		round.P1 = move
+	case g.P2:
+		if round.P2 != "" {
+			return MoveResult{}, errors.New("move already submitted")
+		}
+		round.P2 = move
+	default:
+		return MoveResult{}, errors.New("not a player in this game")
+	}
+
+	now := g.clock.Now()
+	round.UpdatedAt = now
+	g.LastActivity = now
+	g.emit(MoveSubmitted{EventMeta: g.metaAt(now), PlayerID: player, Round: round.Number, Move: move})
+
+	if round.P1 != "" && round.P2 != "" {
+		result, err := g.rules.Resolve(round.P1, round.P2)
+		if err != nil {
+			return MoveResult{}, err
+		}
+		if err := g.finishRound(round.P1, round.P2, result); err != nil {
+			return MoveResult{}, err
+		}
+	}
+
+	return g.moveResult(round.Number), nil
+}
+
+// moveResult reports on the given round after a move. Must be called with
+// the mutex held.
+func (g *Game) moveResult(number int) MoveResult {
+	if g.CurrentRound != nil && g.CurrentRound.Number == number {
+		return MoveResult{Round: *g.CurrentRound}
+	}
+	return MoveResult{
+		Round:    g.Rounds[len(g.Rounds)-1],
+		Resolved: true,
+		GameOver: g.isOver(),
+	}
+}
+
+// ExpireRound applies the timeout policy to the given round once its
+// deadline has passed. It reports false without changing anything when the
+// round is no longer current or has not yet expired, so a stale timer firing
+// late is harmless. A commit phase that expires under the random policy moves
+// on to the reveal phase with a fresh deadline instead of resolving.
+func (g *Game) ExpireRound(number int) (bool, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	round := g.CurrentRound
+	if g.isOver() || round == nil || round.Number != number {
+		return false, nil
+	}
+	if round.Deadline.IsZero() || g.clock.Now().Before(round.Deadline) {
+		return false, nil
+	}
+
+	round.TimedOut = true
+
+	if round.Phase == PhaseReveal {
+		// A missing reveal always forfeits, whatever the policy.
+		g.recordMissingReveals()
+		return true, g.forfeitRound(round.P1 != "", round.P2 != "")
+	}
+
+	switch g.Config.TimeoutPolicy {
+	case TimeoutAbort:
+		if err := g.transition(StateAbandoned); err != nil {
+			return false, err
+		}
+		g.Rounds = append(g.Rounds, *round)
+		g.CurrentRound = nil
+		g.EndReason = EndReasonAborted
+		g.LastActivity = g.clock.Now()
+		g.emit(RoundResolved{EventMeta: g.metaAt(g.LastActivity), Round: *round})
+		g.emitFinished()
+	case TimeoutRandom:
+		if round.Phase == PhaseCommit {
+			return true, g.expireCommitPhase()
+		}
+
+		p1Move, p2Move := round.P1, round.P2
+		if p1Move == "" {
+			p1Move = g.randomMove()
+		}
+		if p2Move == "" {
+			p2Move = g.randomMove()
+		}
+
+		result, err := g.rules.Resolve(p1Move, p2Move)
+		if err != nil {
+			return false, err
+		}
+		return true, g.finishRound(p1Move, p2Move, result)
+	default:
+		if round.Phase == PhaseCommit {
+			return true, g.forfeitRound(round.P1Commit != "", round.P2Commit != "")
+		}
+		return true, g.forfeitRound(round.P1 != "", round.P2 != "")
+	}
+
+	return true, nil
+}
+
+// forfeitRound gives the current round to the only player who moved, draws
+// it when neither did, and resolves it normally when both did.
+func (g *Game) forfeitRound(p1Moved, p2Moved bool) error {
+	round := g.CurrentRound
+
+	result := "draw"
+	switch {
+	case p1Moved && p2Moved:
+		var err error
+		result, err = g.rules.Resolve(round.P1, round.P2)
+		if err != nil {
+			return err
+		}
+	case p1Moved:
+		result = "p1"
+	case p2Moved:
+		result = "p2"
+	}
+
+	return g.finishRound(round.P1, round.P2, result)
+}
+
+func (g *Game) randomMove() Move {
+	return g.rules.Moves[rand.IntN(len(g.rules.Moves))]
+}
+
+func (g *Game) finishRound(p1Move, p2Move Move, result string) error {
+	if err := g.transition(StateRoundResolved); err != nil {
+		return err
+	}
+
+	now := g.clock.Now()
+
 	g.CurrentRound.P1 = p1Move
 	g.CurrentRound.P2 = p2Move
 	g.CurrentRound.Winner = result
+	g.CurrentRound.UpdatedAt = now
 
 	switch result {
 	case "p1":
@@ -98,6 +427,107 @@ func (g *Game) PlayRound(p1Move, p2Move Move) error {
 
 	g.Rounds = append(g.Rounds, *g.CurrentRound)
 	g.CurrentRound = nil
+	g.LastActivity = now
+	g.emit(RoundResolved{EventMeta: g.metaAt(now), Round: g.Rounds[len(g.Rounds)-1]})
 
+	return g.checkWinner()
+}
+
+// roundsExhausted reports whether the config allows no further rounds. A tied
+// best-of-N with sudden death enabled keeps going past its round limit.
+func (g *Game) roundsExhausted() bool {
+	c := g.Config
+
+	if c.MaxRounds > 0 && len(g.Rounds) >= c.MaxRounds {
+		return true
+	}
+	if c.Format != FormatBestOf {
+		return false
+	}
+	if c.SuddenDeath && g.P1Wins == g.P2Wins {
+		return false
+	}
+	return g.countedRounds() >= c.Rounds
+}
+
+func (g *Game) countedRounds() int {
+	if g.Config.DrawsCount {
+		return len(g.Rounds)
+	}
+	return g.P1Wins + g.P2Wins
+}
+
+// checkWinner ends the game once a player has clinched the match, or once the
+// config allows no further rounds. A tied score at that point ends the game
+// without a winner.
+func (g *Game) checkWinner() error {
+	needed := g.Config.WinsNeeded()
+
+	switch {
+	case g.Config.Format == FormatBestOf && g.countedRounds() > g.Config.Rounds && g.P1Wins != g.P2Wins:
+		return g.finishWithLeader(EndReasonSuddenDeath)
+	case g.P1Wins >= needed, g.P2Wins >= needed:
+		return g.finishWithLeader(EndReasonMajority)
+	case g.Config.MaxRounds > 0 && len(g.Rounds) >= g.Config.MaxRounds:
+		return g.finishWithLeader(EndReasonRoundCap)
+	case g.roundsExhausted():
+		return g.finishWithLeader(EndReasonMaxRounds)
+	}
+	return nil
+}
+
+func (g *Game) finishWithLeader(reason string) error {
+	if err := g.transition(StateFinished); err != nil {
+		return err
+	}
+	g.EndReason = reason
+	if g.P1Wins > g.P2Wins {
+		g.Winner = g.P1
+	} else if g.P2Wins > g.P1Wins {
+		g.Winner = g.P2
+	}
+	g.emitFinished()
 	return nil
 }
+
+func (g *Game) emitFinished() {
+	g.emit(GameFinished{
+		EventMeta: g.meta(),
+		Winner:    g.Winner,
+		Reason:    g.EndReason,
+		P1Wins:    g.P1Wins,
+		P2Wins:    g.P2Wins,
+	})
+}
+
+func (g *Game) Result() GameResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	rounds := make([]RoundSummary, len(g.Rounds))
+	for i, r := range g.Rounds {
+		rounds[i] = RoundSummary{
+			Number:   i + 1,
+			P1:       r.P1,
+			P2:       r.P2,
+			Winner:   r.Winner,
+			TimedOut: r.TimedOut,
+		}
+	}
+
+	return GameResult{
+		Winner: g.Winner,
+		Reason: g.EndReason,
+		P1Wins: g.P1Wins,
+		P2Wins: g.P2Wins,
+		Rounds: rounds,
+	}
+}
+
+// History returns a copy of the finished rounds.
+func (g *Game) History() []Round {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]Round(nil), g.Rounds...)
+}
@@ -1,6 +1,9 @@
 package game
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNewGame(t *testing.T) {
 	t.Run("Create new game", func(t *testing.T) {
@@ -71,6 +74,83 @@ func TestSetPlayerConnected(t *testing.T) {
 	})
 }
 
+func TestAbandon(t *testing.T) {
+	t.Run("Opponent wins when a player leaves", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		game.SetPlayerConnected("Alice", false)
+
+		abandoned, err := game.Abandon("Alice")
+		if err != nil || !abandoned {
+			t.Fatalf("Expected the game to be abandoned, got %v, %v", abandoned, err)
+		}
+		if game.State != StateFinished || game.Winner != "Bob" || game.EndReason != EndReasonAbandoned {
+			t.Errorf("Expected Bob to win by abandonment, got '%s' by '%s' in %s", game.Winner, game.EndReason, game.State)
+		}
+		if game.CurrentRound != nil || len(game.Rounds) != 0 {
+			t.Error("Expected the round in progress to be dropped")
+		}
+	})
+
+	t.Run("Does nothing once the player is back", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.SetPlayerConnected("Bob", false)
+		game.SetPlayerConnected("Bob", true)
+		if abandoned, _ := game.Abandon("Bob"); abandoned || game.IsOver() {
+			t.Error("Expected a connected player not to forfeit")
+		}
+	})
+
+	t.Run("Does nothing without an opponent", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		if abandoned, _ := game.Abandon("Alice"); abandoned || game.IsOver() {
+			t.Error("Expected a game without an opponent not to be forfeited")
+		}
+	})
+
+	t.Run("Aborts when both players are gone", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+		game.NewRound()
+		game.SetPlayerConnected("Bob", false)
+		game.SetPlayerConnected("Alice", false)
+
+		abandoned, err := game.Abandon("Bob")
+		if err != nil || !abandoned {
+			t.Fatalf("Expected the game to end, got %v, %v", abandoned, err)
+		}
+		if game.State != StateAbandoned || game.Winner != "" || game.EndReason != EndReasonAborted {
+			t.Errorf("Expected an aborted game without winner, got '%s' by '%s' in %s", game.Winner, game.EndReason, game.State)
+		}
+		if game.CurrentRound != nil {
+			t.Error("Expected the round in progress to be dropped")
+		}
+	})
+
+	t.Run("Does nothing once the game is over", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+		game.SetPlayerConnected("Alice", false)
+		game.Abandon("Alice")
+		game.SetPlayerConnected("Bob", false)
+		if abandoned, _ := game.Abandon("Bob"); abandoned || game.Winner != "Bob" {
+			t.Errorf("Expected the first forfeit to stand, got winner '%s'", game.Winner)
+		}
+	})
+
+	t.Run("Rejects other players", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		if _, err := game.Abandon("Carol"); err == nil {
+			t.Error("Expected an error for a player not in the game")
+		}
+	})
+}
+
 func TestIsActive(t *testing.T) {
 	t.Run("Both players disconnected", func(t *testing.T) {
 		game := NewGame("game1", "Alice", "Bob")
@@ -117,12 +197,13 @@ func TestNewRound(t *testing.T) {
 
 	t.Run("Create multiple rounds", func(t *testing.T) {
 		game := NewGame("game1", "Alice", "Bob")
-		for i := 0; i < MaxRounds; i++ {
+		for i := 0; i < game.Config.Rounds; i++ {
 			_, err := game.NewRound()
 			if err != nil {
 				t.Errorf("Expected no error on round %d, got %v", i+1, err)
 			}
 			game.CurrentRound = nil
+			game.State = StateRoundResolved
 			game.Rounds = append(game.Rounds, Round{})
 		}
 		_, err := game.NewRound()
@@ -142,7 +223,7 @@ func TestNewRound(t *testing.T) {
 
 	t.Run("Cannot create new round if max rounds reached", func(t *testing.T) {
 		game := NewGame("game1", "Alice", "Bob")
-		for i := 0; i < MaxRounds; i++ {
+		for i := 0; i < game.Config.Rounds; i++ {
 			game.Rounds = append(game.Rounds, Round{})
 		}
 		_, err := game.NewRound()
@@ -176,7 +257,8 @@ func TestPlayRound(t *testing.T) {
 
 	t.Run("Play multiple rounds until game over", func(t *testing.T) {
 		game := NewGame("game1", "Alice", "Bob")
-		for i := 0; i < MaxRounds; i++ {
+		needed := game.Config.WinsNeeded()
+		for i := 0; i < needed; i++ {
 			_, err := game.NewRound()
 			if err != nil {
 				t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
@@ -186,15 +268,81 @@ func TestPlayRound(t *testing.T) {
 				t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
 			}
 		}
-		if game.P1Wins != MaxRounds {
-			t.Errorf("Expected P1Wins to be %d, got %d", MaxRounds, game.P1Wins)
+		if game.P1Wins != needed {
+			t.Errorf("Expected P1Wins to be %d, got %d", needed, game.P1Wins)
 		}
-		if game.Winner != "" {
-			t.Errorf("Expected no winner yet, got %s", game.Winner)
+		if game.Winner != "Alice" {
+			t.Errorf("Expected winner to be 'Alice', got '%s'", game.Winner)
+		}
+		if game.EndReason != EndReasonMajority {
+			t.Errorf("Expected end reason '%s', got '%s'", EndReasonMajority, game.EndReason)
+		}
+		if _, err := game.NewRound(); err == nil {
+			t.Errorf("Expected error creating round after game over, got nil")
 		}
 		err := game.PlayRound(Rock, Scissors)
 		if err == nil {
-			t.Errorf("Expected error playing round after max rounds, got nil")
+			t.Errorf("Expected error playing round after game over, got nil")
+		}
+	})
+
+	t.Run("P2 clinches majority", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		moves := [][2]Move{{Rock, Scissors}, {Rock, Paper}, {Scissors, Rock}}
+		for i, m := range moves {
+			if _, err := game.NewRound(); err != nil {
+				t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
+			}
+			if err := game.PlayRound(m[0], m[1]); err != nil {
+				t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
+			}
+		}
+		if game.Winner != "Bob" {
+			t.Errorf("Expected winner to be 'Bob', got '%s'", game.Winner)
+		}
+		if game.EndReason != EndReasonMajority {
+			t.Errorf("Expected end reason '%s', got '%s'", EndReasonMajority, game.EndReason)
+		}
+	})
+
+	t.Run("Max rounds reached with more wins", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		moves := [][2]Move{{Rock, Scissors}, {Rock, Rock}, {Paper, Paper}}
+		for i, m := range moves {
+			if _, err := game.NewRound(); err != nil {
+				t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
+			}
+			if err := game.PlayRound(m[0], m[1]); err != nil {
+				t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
+			}
+		}
+		if game.Winner != "Alice" {
+			t.Errorf("Expected winner to be 'Alice', got '%s'", game.Winner)
+		}
+		if game.EndReason != EndReasonMaxRounds {
+			t.Errorf("Expected end reason '%s', got '%s'", EndReasonMaxRounds, game.EndReason)
+		}
+	})
+
+	t.Run("Max rounds reached with tied score", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		moves := [][2]Move{{Rock, Scissors}, {Rock, Paper}, {Paper, Paper}}
+		for i, m := range moves {
+			if _, err := game.NewRound(); err != nil {
+				t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
+			}
+			if err := game.PlayRound(m[0], m[1]); err != nil {
+				t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
+			}
+		}
+		if game.Winner != "" {
+			t.Errorf("Expected no winner, got '%s'", game.Winner)
+		}
+		if !game.IsOver() {
+			t.Errorf("Expected game to be over")
+		}
+		if game.EndReason != EndReasonMaxRounds {
+			t.Errorf("Expected end reason '%s', got '%s'", EndReasonMaxRounds, game.EndReason)
 		}
 	})
 
@@ -251,3 +399,205 @@ func TestPlayRound(t *testing.T) {
 		}
 	})
 }
+
+func TestResult(t *testing.T) {
+	game := NewGame("game1", "Alice", "Bob")
+	moves := [][2]Move{{Rock, Scissors}, {Rock, Rock}, {Paper, Rock}}
+	for i, m := range moves {
+		if _, err := game.NewRound(); err != nil {
+			t.Fatalf("Expected no error creating new round %d, got %v", i+1, err)
+		}
+		if err := game.PlayRound(m[0], m[1]); err != nil {
+			t.Fatalf("Expected no error playing round %d, got %v", i+1, err)
+		}
+	}
+
+	result := game.Result()
+	if result.Winner != "Alice" || result.Reason != EndReasonMajority {
+		t.Errorf("Expected 'Alice' to win by majority, got '%s' by '%s'", result.Winner, result.Reason)
+	}
+	if result.P1Wins != 2 || result.P2Wins != 0 {
+		t.Errorf("Expected score 2-0, got %d-%d", result.P1Wins, result.P2Wins)
+	}
+	if len(result.Rounds) != 3 {
+		t.Fatalf("Expected 3 round summaries, got %d", len(result.Rounds))
+	}
+	if result.Rounds[1].Number != 2 || result.Rounds[1].Winner != "draw" {
+		t.Errorf("Expected round 2 to be a draw, got %+v", result.Rounds[1])
+	}
+}
+
+func newTimedGame(policy TimeoutPolicy) (*Game, *MockClock) {
+	clock := NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+	config := DefaultMatchConfig()
+	config.MoveTimeout = 10 * time.Second
+	config.TimeoutPolicy = policy
+	return newGame("game1", "Alice", "Bob", config, clock), clock
+}
+
+func TestExpireRound(t *testing.T) {
+	t.Run("Round gets a deadline", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, err := game.NewRound()
+		if err != nil {
+			t.Fatalf("Expected no error creating new round, got %v", err)
+		}
+		if !round.Deadline.Equal(clock.Now().Add(10 * time.Second)) {
+			t.Errorf("Expected deadline 10s from now, got %v", round.Deadline)
+		}
+	})
+
+	t.Run("Round without timeout has no deadline", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		round, err := game.NewRound()
+		if err != nil {
+			t.Fatalf("Expected no error creating new round, got %v", err)
+		}
+		if !round.Deadline.IsZero() {
+			t.Errorf("Expected no deadline, got %v", round.Deadline)
+		}
+	})
+
+	t.Run("Does nothing before the deadline", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, _ := game.NewRound()
+		clock.Advance(5 * time.Second)
+		expired, err := game.ExpireRound(round.Number)
+		if err != nil || expired {
+			t.Errorf("Expected round not to expire, got %v, %v", expired, err)
+		}
+	})
+
+	t.Run("Ignores stale round numbers", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, _ := game.NewRound()
+		clock.Advance(time.Minute)
+		expired, err := game.ExpireRound(round.Number + 1)
+		if err != nil || expired {
+			t.Errorf("Expected stale round not to expire, got %v, %v", expired, err)
+		}
+	})
+
+	t.Run("Forfeit gives the round to the player who moved", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, _ := game.NewRound()
+		game.SubmitMove("Bob", Rock)
+		clock.Advance(10 * time.Second)
+		expired, err := game.ExpireRound(round.Number)
+		if err != nil || !expired {
+			t.Fatalf("Expected round to expire, got %v, %v", expired, err)
+		}
+		if game.P2Wins != 1 || game.Rounds[0].Winner != "p2" || !game.Rounds[0].TimedOut {
+			t.Errorf("Expected P2 to win a timed out round, got %+v", game.Rounds[0])
+		}
+	})
+
+	t.Run("Forfeit draws when nobody moved", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, _ := game.NewRound()
+		clock.Advance(time.Minute)
+		if _, err := game.ExpireRound(round.Number); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if game.Rounds[0].Winner != "draw" {
+			t.Errorf("Expected draw, got %s", game.Rounds[0].Winner)
+		}
+	})
+
+	t.Run("Random plays a move for the missing player", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutRandom)
+		round, _ := game.NewRound()
+		game.SubmitMove("Alice", Paper)
+		clock.Advance(time.Minute)
+		if _, err := game.ExpireRound(round.Number); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		played := game.Rounds[0]
+		if played.P1 != Paper || !played.P2.IsValidMove() {
+			t.Errorf("Expected paper and a random valid move, got %s and %s", played.P1, played.P2)
+		}
+	})
+
+	t.Run("Abort ends the game without a winner", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutAbort)
+		round, _ := game.NewRound()
+		clock.Advance(time.Minute)
+		if _, err := game.ExpireRound(round.Number); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !game.IsOver() || game.Winner != "" || game.EndReason != EndReasonAborted {
+			t.Errorf("Expected aborted game without winner, got '%s' by '%s'", game.Winner, game.EndReason)
+		}
+	})
+
+	t.Run("Timer from the clock expires the round", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutForfeit)
+		round, _ := game.NewRound()
+		clock.AfterFunc(round.Deadline.Sub(clock.Now()), func() {
+			game.ExpireRound(round.Number)
+		})
+		clock.Advance(10 * time.Second)
+		if game.CurrentRound != nil || len(game.Rounds) != 1 {
+			t.Errorf("Expected round to be resolved by the timer")
+		}
+	})
+}
+
+func TestSubmitMove(t *testing.T) {
+	t.Run("First move waits for the opponent", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		result, err := game.SubmitMove("Alice", Rock)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Resolved || result.Round.P1 != Rock {
+			t.Errorf("Expected unresolved round with P1 rock, got %+v", result)
+		}
+	})
+
+	t.Run("Second move resolves the round", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		game.SubmitMove("Bob", Paper)
+		result, err := game.SubmitMove("Alice", Rock)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Resolved || result.Round.Winner != "p2" || result.GameOver {
+			t.Errorf("Expected round won by P2 with the game still on, got %+v", result)
+		}
+	})
+
+	t.Run("Reports game over", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		game.SubmitMove("Bob", Scissors)
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		result, _ := game.SubmitMove("Bob", Scissors)
+		if !result.GameOver {
+			t.Errorf("Expected game over")
+		}
+	})
+
+	t.Run("Rejects invalid submissions", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		if _, err := game.SubmitMove("Alice", Rock); err == nil {
+			t.Errorf("Expected error without an active round, got nil")
+		}
+
+		game.NewRound()
+		if _, err := game.SubmitMove("Eve", Rock); err == nil {
+			t.Errorf("Expected error for a non-player, got nil")
+		}
+		if _, err := game.SubmitMove("Alice", Spock); err == nil {
+			t.Errorf("Expected error for a move outside the rule set, got nil")
+		}
+		game.SubmitMove("Alice", Rock)
+		if _, err := game.SubmitMove("Alice", Paper); err == nil {
+			t.Errorf("Expected error for a second move, got nil")
+		}
+	})
+}
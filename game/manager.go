@@ -9,24 +9,93 @@ import (
 type Manager struct {
 	games         map[string]*Game
 	uuidGenerator UUIDGenerator
+	clock         Clock
+	bus           EventBus
+	store         Store
+	storeErrors   func(Event, error)
 	mu            sync.RWMutex
 }
 
-func NewManager() *Manager {
-	return &Manager{
+type ManagerOption func(*Manager)
+
+func WithUUIDGenerator(generator UUIDGenerator) ManagerOption {
+	return func(gm *Manager) {
+		gm.uuidGenerator = generator
+	}
+}
+
+func WithClock(clock Clock) ManagerOption {
+	return func(gm *Manager) {
+		gm.clock = clock
+	}
+}
+
+func WithEventBus(bus EventBus) ManagerOption {
+	return func(gm *Manager) {
+		gm.bus = bus
+	}
+}
+
+// WithStore makes the manager save every change to its games in store.
+func WithStore(store Store) ManagerOption {
+	return func(gm *Manager) {
+		gm.store = store
+	}
+}
+
+// WithStoreErrors calls handler with every event the store failed to save,
+// instead of logging it.
+func WithStoreErrors(handler func(event Event, err error)) ManagerOption {
+	return func(gm *Manager) {
+		gm.storeErrors = handler
+	}
+}
+
+func NewManager(opts ...ManagerOption) *Manager {
+	gm := &Manager{
 		games:         make(map[string]*Game),
 		uuidGenerator: &DefaultUUIDGenerator{},
+		clock:         RealClock{},
+		bus:           NewInMemoryEventBus(),
+		storeErrors:   logStoreError,
+	}
+	for _, opt := range opts {
+		opt(gm)
 	}
+	if gm.store != nil {
+		gm.bus.Subscribe(gm.persist)
+	}
+	return gm
 }
 
 func NewManagerWithUUIDGenerator(generator UUIDGenerator) *Manager {
-	return &Manager{
-		games:         make(map[string]*Game),
-		uuidGenerator: generator,
+	return NewManager(WithUUIDGenerator(generator))
+}
+
+func (gm *Manager) Clock() Clock {
+	return gm.clock
+}
+
+// Events is the bus every game created by the manager publishes to.
+func (gm *Manager) Events() EventBus {
+	return gm.bus
+}
+
+func (gm *Manager) CreateGame(p1, p2 string, config MatchConfig) (*Game, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	game, err := gm.insertGame(p1, p2, config)
+	if err != nil {
+		return nil, err
 	}
+
+	game.flush()
+	return game, nil
 }
 
-func (gm *Manager) CreateGame(p1, p2 string) (*Game, error) {
+func (gm *Manager) insertGame(p1, p2 string, config MatchConfig) (*Game, error) {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
@@ -43,7 +112,10 @@ func (gm *Manager) CreateGame(p1, p2 string) (*Game, error) {
 		}
 	}
 
-	game := NewGame(id, p1, p2)
+	game := newGame(id, p1, p2, config, gm.clock)
+	game.bus = gm.bus
+	game.emitCreated()
+
 	gm.games[id] = game
 	return game, nil
 }
@@ -72,8 +144,9 @@ func (gm *Manager) CleanupExpiredGames(maxAge time.Duration) {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
+	now := gm.clock.Now()
 	for id, game := range gm.games {
-		if time.Since(game.LastActivity) > maxAge {
+		if now.Sub(game.lastActivity()) > maxAge {
 			delete(gm.games, id)
 		}
 	}
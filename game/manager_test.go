@@ -18,7 +18,7 @@ func TestNewManager(t *testing.T) {
 func TestCreateGame(t *testing.T) {
 	t.Run("Create a new game", func(t *testing.T) {
 		m := NewManager()
-		game, err := m.CreateGame("Alice", "Bob")
+		game, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
@@ -41,7 +41,7 @@ func TestCreateGame(t *testing.T) {
 		mockGenerator := NewMockUUIDGenerator(mockIDs)
 		m := NewManagerWithUUIDGenerator(mockGenerator)
 
-		game1, err := m.CreateGame("Alice", "Bob")
+		game1, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 		if err != nil {
 			t.Fatalf("Expected no error for first game, got %v", err)
 		}
@@ -49,7 +49,7 @@ func TestCreateGame(t *testing.T) {
 			t.Errorf("Expected first game ID to be 'fixed-id', got '%s'", game1.ID)
 		}
 
-		game2, err := m.CreateGame("Charlie", "Dave")
+		game2, err := m.CreateGame("Charlie", "Dave", DefaultMatchConfig())
 		if err != nil {
 			t.Fatalf("Expected no error for second game, got %v", err)
 		}
@@ -67,11 +67,11 @@ func TestCreateGame(t *testing.T) {
 		mockGenerator := NewMockUUIDGenerator(mockIDs)
 		m := NewManagerWithUUIDGenerator(mockGenerator)
 
-		_, err := m.CreateGame("Alice", "Bob")
+		_, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 		if err != nil {
 			t.Fatal("Expected no error for first game, got nil")
 		}
-		_, err = m.CreateGame("Charlie", "Dave")
+		_, err = m.CreateGame("Charlie", "Dave", DefaultMatchConfig())
 		if err == nil {
 			t.Fatal("Expected error for exceeding max retries, got nil")
 		}
@@ -80,7 +80,7 @@ func TestCreateGame(t *testing.T) {
 
 func TestGetGame(t *testing.T) {
 	m := NewManager()
-	game, err := m.CreateGame("Alice", "Bob")
+	game, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -116,7 +116,7 @@ func TestGetGame(t *testing.T) {
 
 func TestRemoveGame(t *testing.T) {
 	m := NewManager()
-	game, err := m.CreateGame("Alice", "Bob")
+	game, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -147,11 +147,11 @@ func TestRemoveGame(t *testing.T) {
 
 func TestCleanupExpiredGames(t *testing.T) {
 	m := NewManager()
-	game1, err := m.CreateGame("Alice", "Bob")
+	game1, err := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	game2, err := m.CreateGame("Charlie", "Dave")
+	game2, err := m.CreateGame("Charlie", "Dave", DefaultMatchConfig())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -1,8 +1,15 @@
 package models
 
+import "ldriko/rps-backend/rating"
+
 type Player struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	CreatedAt int64  `json:"createdAt"`
+	ID        string        `json:"id"`
+	Username  string        `json:"username"`
+	Email     string        `json:"email"`
+	CreatedAt int64         `json:"createdAt"`
+	Rating    rating.Rating `json:"rating"`
+
+	// PasswordHash is never sent to clients; repositories store it
+	// themselves.
+	PasswordHash string `json:"-"`
 }
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"sync"
+	"testing"
+)
+
+// These tests are meant to be run with -race.
+
+func TestConcurrentSubmitMove(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		game := NewGame("game1", "Alice", "Bob")
+		if _, err := game.NewRound(); err != nil {
+			t.Fatalf("Expected no error creating new round, got %v", err)
+		}
+
+		var wg sync.WaitGroup
+		results := make(chan MoveResult, 2)
+		for _, player := range []struct {
+			id   string
+			move Move
+		}{{"Alice", Rock}, {"Bob", Scissors}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := game.SubmitMove(player.id, player.move)
+				if err != nil {
+					t.Errorf("Expected no error submitting move, got %v", err)
+					return
+				}
+				results <- result
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		resolved := 0
+		for result := range results {
+			if result.Resolved {
+				resolved++
+			}
+		}
+		if resolved != 1 {
+			t.Fatalf("Expected exactly one move to resolve the round, got %d", resolved)
+		}
+		if len(game.Rounds) != 1 || game.P1Wins != 1 {
+			t.Fatalf("Expected one round won by P1, got %d rounds and %d wins", len(game.Rounds), game.P1Wins)
+		}
+	}
+}
+
+func TestConcurrentGameplay(t *testing.T) {
+	config := DefaultMatchConfig()
+	config.Format = FormatFirstTo
+	config.Rounds = 20
+	game := NewGameWithConfig("game1", "Alice", "Bob", config)
+
+	var wg sync.WaitGroup
+	play := func(player string, move Move) {
+		defer wg.Done()
+		for !game.IsOver() {
+			game.NewRound()
+			game.SubmitMove(player, move)
+		}
+	}
+	watch := func() {
+		defer wg.Done()
+		for !game.IsOver() {
+			game.ViewFor("Alice")
+			game.SpectatorView()
+			game.Result()
+			game.SetPlayerConnected("Bob", true)
+		}
+	}
+
+	wg.Add(4)
+	go play("Alice", Paper)
+	go play("Bob", Rock)
+	go watch()
+	go watch()
+	wg.Wait()
+
+	result := game.Result()
+	if result.Winner != "Alice" || result.P1Wins != 20 || result.P2Wins != 0 {
+		t.Errorf("Expected 'Alice' to win 20-0, got '%s' %d-%d", result.Winner, result.P1Wins, result.P2Wins)
+	}
+	for _, r := range result.Rounds {
+		if r.P1 != Paper || r.P2 != Rock {
+			t.Fatalf("Expected every round to be paper vs rock, got %+v", r)
+		}
+	}
+}
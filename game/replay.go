@@ -0,0 +1,176 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Replay rebuilds a game from its event log. The log must start with the
+// GameCreated event; the game it returns has the same state, rounds, score
+// and timestamps as the game that emitted the events, and carries the log
+// itself. It is not attached to an event bus.
+func Replay(events []Event) (*Game, error) {
+	if len(events) == 0 {
+		return nil, errors.New("no events to replay")
+	}
+	created, ok := events[0].(GameCreated)
+	if !ok {
+		return nil, fmt.Errorf("event log starts with %s, not %s", events[0].Type(), EventGameCreated)
+	}
+
+	g := newGame(created.Game, created.P1, created.P2, created.Config, RealClock{})
+	g.CreatedAt = created.At
+	g.LastActivity = created.At
+	g.log = append(g.log, created)
+
+	for _, event := range events[1:] {
+		if event.GameID() != g.ID {
+			return nil, fmt.Errorf("event %s belongs to game %s, not %s", event.Type(), event.GameID(), g.ID)
+		}
+		if err := g.apply(event); err != nil {
+			return nil, fmt.Errorf("replaying %s: %w", event.Type(), err)
+		}
+		g.log = append(g.log, event)
+	}
+	return g, nil
+}
+
+// apply makes the change to the game that the event recorded.
+func (g *Game) apply(event Event) error {
+	switch e := event.(type) {
+	case PlayerJoined:
+		if err := g.transition(StateReady); err != nil {
+			return err
+		}
+		if e.Seat == SeatP1 {
+			g.P1 = e.PlayerID
+		} else {
+			g.P2 = e.PlayerID
+		}
+		g.LastActivity = e.At
+	case GameReady:
+	case PlayerConnected:
+		g.setConnected(e.PlayerID, true)
+		g.LastActivity = e.At
+	case PlayerDisconnected:
+		g.setConnected(e.PlayerID, false)
+		g.LastActivity = e.At
+	case RoundStarted:
+		if err := g.transition(StateRoundInProgress); err != nil {
+			return err
+		}
+		round := e.Round
+		g.CurrentRound = &round
+	case MoveSubmitted:
+		round, err := g.replayRound(e.Round)
+		if err != nil {
+			return err
+		}
+		if e.PlayerID == g.P1 {
+			round.P1 = e.Move
+		} else {
+			round.P2 = e.Move
+		}
+		round.UpdatedAt = e.At
+		g.LastActivity = e.At
+	case MoveCommitted:
+		round, err := g.replayRound(e.Round)
+		if err != nil {
+			return err
+		}
+		if e.PlayerID == g.P1 {
+			round.P1Commit = e.Commitment
+		} else {
+			round.P2Commit = e.Commitment
+		}
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player:     e.PlayerID,
+			Action:     ActionCommit,
+			Commitment: e.Commitment,
+			Valid:      true,
+			At:         e.At,
+		})
+		round.UpdatedAt = e.At
+		g.LastActivity = e.At
+	case RevealPhaseStarted:
+		round := e.Round
+		g.CurrentRound = &round
+	case MoveRevealed:
+		round, err := g.replayRound(e.Round)
+		if err != nil {
+			return err
+		}
+		round.Transcript = append(round.Transcript, TranscriptEntry{
+			Player: e.PlayerID,
+			Action: ActionReveal,
+			Move:   e.Move,
+			Nonce:  e.Nonce,
+			Valid:  e.Valid,
+			At:     e.At,
+		})
+		if e.Valid {
+			if e.PlayerID == g.P1 {
+				round.P1 = e.Move
+			} else {
+				round.P2 = e.Move
+			}
+		}
+		round.UpdatedAt = e.At
+		g.LastActivity = e.At
+	case RoundResolved:
+		// Only a round that aborted the game is left without a winner.
+		next := StateRoundResolved
+		if e.Round.Winner == "" {
+			next = StateAbandoned
+		}
+		if err := g.transition(next); err != nil {
+			return err
+		}
+		switch e.Round.Winner {
+		case "p1":
+			g.P1Wins++
+		case "p2":
+			g.P2Wins++
+		}
+		g.Rounds = append(g.Rounds, e.Round)
+		g.CurrentRound = nil
+		g.LastActivity = e.At
+	case GameFinished:
+		next := StateFinished
+		if e.Reason == EndReasonAborted {
+			next = StateAbandoned
+		}
+		// A round that timed out under the abort policy has already
+		// abandoned the game.
+		if next != StateAbandoned || g.State != StateAbandoned {
+			if err := g.transition(next); err != nil {
+				return err
+			}
+		}
+		// A game that ends mid-round drops the round.
+		g.CurrentRound = nil
+		g.Winner = e.Winner
+		g.EndReason = e.Reason
+		g.P1Wins = e.P1Wins
+		g.P2Wins = e.P2Wins
+	default:
+		return fmt.Errorf("unexpected event %s", event.Type())
+	}
+	return nil
+}
+
+func (g *Game) replayRound(number int) (*Round, error) {
+	if g.CurrentRound == nil || g.CurrentRound.Number != number {
+		return nil, fmt.Errorf("round %d is not in progress", number)
+	}
+	return g.CurrentRound, nil
+}
+
+func (g *Game) setConnected(player string, connected bool) {
+	switch player {
+	case g.P1:
+		g.P1Connected = connected
+	case g.P2:
+		g.P2Connected = connected
+	}
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newReplayManager() (*Manager, *MockClock) {
+	clock := NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+	return NewManager(WithClock(clock)), clock
+}
+
+// assertReplays checks that replaying the game's log, after a round trip
+// through JSON, rebuilds the same game.
+func assertReplays(t *testing.T, game *Game) {
+	t.Helper()
+
+	var events []Event
+	for _, event := range game.EventLog() {
+		data, err := MarshalEvent(event)
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", event.Type(), err)
+		}
+		decoded, err := UnmarshalEvent(data)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", data, err)
+		}
+		events = append(events, decoded)
+	}
+
+	replayed, err := Replay(events)
+	if err != nil {
+		t.Fatalf("Expected no error replaying, got %v", err)
+	}
+	if want, got := game.Record(), replayed.Record(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Replayed game differs\nwant %+v\ngot  %+v", want, got)
+	}
+	if replayed.P1Connected != game.P1Connected || replayed.P2Connected != game.P2Connected {
+		t.Errorf("Expected connections %v/%v, got %v/%v",
+			game.P1Connected, game.P2Connected, replayed.P1Connected, replayed.P2Connected)
+	}
+	if len(replayed.EventLog()) != len(events) {
+		t.Errorf("Expected the replayed game to keep its %d events, got %d", len(events), len(replayed.EventLog()))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	t.Run("Full game", func(t *testing.T) {
+		m, clock := newReplayManager()
+		game, _ := m.CreateGame("Alice", "", DefaultMatchConfig())
+		clock.Advance(time.Second)
+		game.AddPlayer("Bob")
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+
+		for _, moves := range [][2]Move{{Rock, Scissors}, {Paper, Paper}, {Paper, Rock}} {
+			clock.Advance(time.Second)
+			game.NewRound()
+			clock.Advance(time.Second)
+			game.SubmitMove("Alice", moves[0])
+			clock.Advance(time.Second)
+			game.SubmitMove("Bob", moves[1])
+		}
+		game.SetPlayerConnected("Bob", false)
+
+		if game.State != StateFinished {
+			t.Fatalf("Expected the game to be finished, got %s", game.State)
+		}
+		assertReplays(t, game)
+	})
+
+	t.Run("Game in progress", func(t *testing.T) {
+		m, clock := newReplayManager()
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.NewRound()
+		clock.Advance(time.Second)
+		game.SubmitMove("Bob", Rock)
+
+		assertReplays(t, game)
+	})
+
+	t.Run("Commit-reveal with a bad reveal", func(t *testing.T) {
+		m, clock := newReplayManager()
+		config := DefaultMatchConfig()
+		config.CommitReveal = true
+		game, _ := m.CreateGame("Alice", "Bob", config)
+
+		game.NewRound()
+		clock.Advance(time.Second)
+		game.CommitMove("Alice", HashMove(Rock, "n1"))
+		game.CommitMove("Bob", HashMove(Paper, "n2"))
+		clock.Advance(time.Second)
+		game.RevealMove("Alice", Rock, "n1")
+		game.RevealMove("Bob", Scissors, "n2")
+
+		if game.P1Wins != 1 {
+			t.Fatalf("Expected Bob to forfeit the round, got %d wins for Alice", game.P1Wins)
+		}
+		assertReplays(t, game)
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		for _, policy := range []TimeoutPolicy{TimeoutForfeit, TimeoutRandom, TimeoutAbort} {
+			m, clock := newReplayManager()
+			config := DefaultMatchConfig()
+			config.CommitReveal = true
+			config.MoveTimeout = 10 * time.Second
+			config.TimeoutPolicy = policy
+			game, _ := m.CreateGame("Alice", "Bob", config)
+
+			round, _ := game.NewRound()
+			game.CommitMove("Alice", HashMove(Rock, "n1"))
+			clock.Advance(10 * time.Second)
+			game.ExpireRound(round.Number)
+
+			assertReplays(t, game)
+		}
+	})
+
+	t.Run("Abandoned game", func(t *testing.T) {
+		m, clock := newReplayManager()
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+		game.NewRound()
+		game.SubmitMove("Bob", Paper)
+		clock.Advance(time.Second)
+		game.SetPlayerConnected("Bob", false)
+		clock.Advance(30 * time.Second)
+		game.Abandon("Bob")
+
+		assertReplays(t, game)
+	})
+
+	t.Run("Game abandoned by both players", func(t *testing.T) {
+		m, clock := newReplayManager()
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.SetPlayerConnected("Alice", true)
+		game.SetPlayerConnected("Bob", true)
+		game.NewRound()
+		game.SetPlayerConnected("Bob", false)
+		game.SetPlayerConnected("Alice", false)
+		clock.Advance(30 * time.Second)
+		game.Abandon("Bob")
+
+		assertReplays(t, game)
+	})
+
+	t.Run("Rejects a log without GameCreated", func(t *testing.T) {
+		if _, err := Replay(nil); err == nil {
+			t.Error("Expected an error replaying no events")
+		}
+		if _, err := Replay([]Event{PlayerJoined{EventMeta: EventMeta{Game: "game1"}}}); err == nil {
+			t.Error("Expected an error replaying a log that does not start with GameCreated")
+		}
+	})
+
+	t.Run("Rejects illegal transitions", func(t *testing.T) {
+		created := GameCreated{EventMeta: EventMeta{Game: "game1"}, P1: "Alice", Config: DefaultMatchConfig()}
+		finished := GameFinished{EventMeta: EventMeta{Game: "game1"}, Winner: "Alice", Reason: EndReasonAbandoned}
+		aborted := GameFinished{EventMeta: EventMeta{Game: "game1"}, Reason: EndReasonAborted}
+
+		if _, err := Replay([]Event{created, finished}); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected a game waiting for an opponent not to finish, got %v", err)
+		}
+		if _, err := Replay([]Event{created, aborted, finished}); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected an abandoned game not to finish, got %v", err)
+		}
+	})
+
+	t.Run("Rejects events from another game", func(t *testing.T) {
+		events := []Event{
+			GameCreated{EventMeta: EventMeta{Game: "game1"}, P1: "Alice", Config: DefaultMatchConfig()},
+			PlayerJoined{EventMeta: EventMeta{Game: "game2"}, PlayerID: "Bob", Seat: SeatP2},
+		}
+		if _, err := Replay(events); err == nil {
+			t.Error("Expected an error replaying events from another game")
+		}
+	})
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	if _, err := UnmarshalEvent([]byte(`{"type":"unknown"}`)); err == nil {
+		t.Error("Expected an error for an unknown event type")
+	}
+}
@@ -1,9 +1,6 @@
 package game
 
-import (
-	"errors"
-	"time"
-)
+import "time"
 
 type Move string
 
@@ -13,38 +10,61 @@ const (
 	Scissors Move = "scissors"
 )
 
+type RoundPhase string
+
+const (
+	// PhaseMove is a plain round where players send their moves directly.
+	PhaseMove RoundPhase = "move"
+	// PhaseCommit collects a hash of each player's move and nonce.
+	PhaseCommit RoundPhase = "commit"
+	// PhaseReveal collects the moves and nonces matching the commitments.
+	PhaseReveal RoundPhase = "reveal"
+)
+
 type Round struct {
-	P1        Move
-	P2        Move
-	Winner    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Number    int        `json:"number"`
+	Phase     RoundPhase `json:"phase"`
+	P1        Move       `json:"p1"`
+	P2        Move       `json:"p2"`
+	Winner    string     `json:"winner"`
+	Deadline  time.Time  `json:"deadline"`
+	TimedOut  bool       `json:"timedOut"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+
+	P1Commit   string            `json:"p1Commit,omitempty"`
+	P2Commit   string            `json:"p2Commit,omitempty"`
+	Transcript []TranscriptEntry `json:"transcript,omitempty"`
+}
+
+// TranscriptEntry records one step of a commit-reveal round so the round can
+// be audited after the fact.
+type TranscriptEntry struct {
+	Player     string    `json:"player"`
+	Action     string    `json:"action"`
+	Commitment string    `json:"commitment,omitempty"`
+	Move       Move      `json:"move,omitempty"`
+	Nonce      string    `json:"nonce,omitempty"`
+	Valid      bool      `json:"valid"`
+	At         time.Time `json:"at"`
+}
+
+type RoundSummary struct {
+	Number   int    `json:"number"`
+	P1       Move   `json:"p1"`
+	P2       Move   `json:"p2"`
+	Winner   string `json:"winner"`
+	TimedOut bool   `json:"timedOut"`
 }
 
 func (m Move) IsValidMove() bool {
-	return m == Rock || m == Paper || m == Scissors
+	return Classic.IsValidMove(m)
 }
 
 func ParseMove(s string) (Move, error) {
-	move := Move(s)
-	if !move.IsValidMove() {
-		return "", errors.New("invalid move")
-	}
-	return move, nil
+	return Classic.ParseMove(s)
 }
 
 func ResolveRound(p1 Move, p2 Move) (string, error) {
-	if !p1.IsValidMove() || !p2.IsValidMove() {
-		return "", errors.New("invalid move")
-	}
-
-	if p1 == p2 {
-		return "draw", nil
-	}
-	if (p1 == Rock && p2 == Scissors) ||
-		(p1 == Paper && p2 == Rock) ||
-		(p1 == Scissors && p2 == Paper) {
-		return "p1", nil
-	}
-	return "p2", nil
+	return Classic.Resolve(p1, p2)
 }
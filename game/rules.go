@@ -0,0 +1,209 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	Lizard Move = "lizard"
+	Spock  Move = "spock"
+)
+
+// RuleSet is a named set of moves together with the dominance graph that
+// decides which move beats which.
+type RuleSet struct {
+	Name  string
+	Moves []Move
+	beats map[Move]map[Move]bool
+}
+
+var (
+	Classic = mustRuleSet(NewCyclicRuleSet("classic", []Move{Rock, Scissors, Paper}))
+	RPSLS   = mustRuleSet(NewRuleSet("rpsls", []Move{Rock, Paper, Scissors, Lizard, Spock}, map[Move][]Move{
+		Rock:     {Scissors, Lizard},
+		Paper:    {Rock, Spock},
+		Scissors: {Paper, Lizard},
+		Lizard:   {Paper, Spock},
+		Spock:    {Rock, Scissors},
+	}))
+	RPS7 = mustRuleSet(NewCyclicRuleSet("rps7", []Move{
+		"rock", "fire", "scissors", "sponge", "paper", "air", "water",
+	}))
+	RPS15 = mustRuleSet(NewCyclicRuleSet("rps15", []Move{
+		"rock", "fire", "scissors", "snake", "human", "tree", "wolf", "sponge",
+		"paper", "air", "water", "dragon", "devil", "lightning", "gun",
+	}))
+)
+
+var (
+	ruleSets = map[string]*RuleSet{
+		Classic.Name: Classic,
+		RPSLS.Name:   RPSLS,
+		RPS7.Name:    RPS7,
+		RPS15.Name:   RPS15,
+	}
+	ruleSetsMu sync.RWMutex
+)
+
+// NewRuleSet builds a rule set where beats maps every move to the moves it
+// defeats. The result is validated to be balanced.
+func NewRuleSet(name string, moves []Move, beats map[Move][]Move) (*RuleSet, error) {
+	rs := &RuleSet{
+		Name:  name,
+		Moves: moves,
+		beats: make(map[Move]map[Move]bool, len(moves)),
+	}
+
+	for winner, losers := range beats {
+		rs.beats[winner] = make(map[Move]bool, len(losers))
+		for _, loser := range losers {
+			rs.beats[winner][loser] = true
+		}
+	}
+
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// NewCyclicRuleSet builds an RPS-101 style rule set from an odd number of
+// moves, where each move beats the (n-1)/2 moves that follow it, wrapping
+// around the end of the list.
+func NewCyclicRuleSet(name string, moves []Move) (*RuleSet, error) {
+	n := len(moves)
+	beats := make(map[Move][]Move, n)
+	for i, move := range moves {
+		for j := 1; j <= (n-1)/2; j++ {
+			beats[move] = append(beats[move], moves[(i+j)%n])
+		}
+	}
+
+	return NewRuleSet(name, moves, beats)
+}
+
+func mustRuleSet(rs *RuleSet, err error) *RuleSet {
+	if err != nil {
+		panic(err)
+	}
+	return rs
+}
+
+// Validate checks that the rule set is balanced: every pair of distinct moves
+// has exactly one winner and every move beats as many moves as it loses to.
+func (rs *RuleSet) Validate() error {
+	if rs.Name == "" {
+		return errors.New("rule set name is required")
+	}
+
+	n := len(rs.Moves)
+	if n < 3 || n%2 == 0 {
+		return fmt.Errorf("rule set %s needs an odd number of moves, at least 3", rs.Name)
+	}
+
+	seen := make(map[Move]bool, n)
+	for _, move := range rs.Moves {
+		if move == "" {
+			return fmt.Errorf("rule set %s has an empty move", rs.Name)
+		}
+		if seen[move] {
+			return fmt.Errorf("rule set %s has duplicate move %s", rs.Name, move)
+		}
+		seen[move] = true
+	}
+
+	for winner, losers := range rs.beats {
+		if !seen[winner] {
+			return fmt.Errorf("rule set %s has unknown move %s", rs.Name, winner)
+		}
+		for loser := range losers {
+			if !seen[loser] {
+				return fmt.Errorf("rule set %s has unknown move %s", rs.Name, loser)
+			}
+		}
+	}
+
+	for _, a := range rs.Moves {
+		wins := 0
+		for _, b := range rs.Moves {
+			if a == b {
+				if rs.Beats(a, a) {
+					return fmt.Errorf("rule set %s has %s beating itself", rs.Name, a)
+				}
+				continue
+			}
+			if rs.Beats(a, b) == rs.Beats(b, a) {
+				return fmt.Errorf("rule set %s has no single winner for %s vs %s", rs.Name, a, b)
+			}
+			if rs.Beats(a, b) {
+				wins++
+			}
+		}
+		if wins != (n-1)/2 {
+			return fmt.Errorf("rule set %s is unbalanced: %s beats %d moves", rs.Name, a, wins)
+		}
+	}
+
+	return nil
+}
+
+func (rs *RuleSet) IsValidMove(m Move) bool {
+	for _, move := range rs.Moves {
+		if move == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *RuleSet) ParseMove(s string) (Move, error) {
+	move := Move(s)
+	if !rs.IsValidMove(move) {
+		return "", errors.New("invalid move")
+	}
+	return move, nil
+}
+
+func (rs *RuleSet) Beats(a, b Move) bool {
+	return rs.beats[a][b]
+}
+
+func (rs *RuleSet) Resolve(p1, p2 Move) (string, error) {
+	if !rs.IsValidMove(p1) || !rs.IsValidMove(p2) {
+		return "", errors.New("invalid move")
+	}
+
+	if p1 == p2 {
+		return "draw", nil
+	}
+	if rs.Beats(p1, p2) {
+		return "p1", nil
+	}
+	return "p2", nil
+}
+
+// RegisterRuleSet makes a custom rule set available to match configs by name.
+func RegisterRuleSet(rs *RuleSet) error {
+	if err := rs.Validate(); err != nil {
+		return err
+	}
+
+	ruleSetsMu.Lock()
+	defer ruleSetsMu.Unlock()
+
+	if _, exists := ruleSets[rs.Name]; exists {
+		return fmt.Errorf("rule set %s already registered", rs.Name)
+	}
+	ruleSets[rs.Name] = rs
+	return nil
+}
+
+func GetRuleSet(name string) (*RuleSet, bool) {
+	ruleSetsMu.RLock()
+	defer ruleSetsMu.RUnlock()
+
+	rs, exists := ruleSets[name]
+	return rs, exists
+}
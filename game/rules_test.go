@@ -0,0 +1,143 @@
+package game
+
+import "testing"
+
+func TestBuiltInRuleSets(t *testing.T) {
+	for _, rs := range []*RuleSet{Classic, RPSLS, RPS7, RPS15} {
+		t.Run("Rule set: "+rs.Name, func(t *testing.T) {
+			if err := rs.Validate(); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if _, exists := GetRuleSet(rs.Name); !exists {
+				t.Errorf("Expected %s to be registered", rs.Name)
+			}
+		})
+	}
+}
+
+func TestRPSLSResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1     Move
+		p2     Move
+		result string
+	}{
+		{"Spock vs Spock", Spock, Spock, "draw"},
+		{"Rock vs Lizard", Rock, Lizard, "p1"},
+		{"Lizard vs Spock", Lizard, Spock, "p1"},
+		{"Spock vs Scissors", Spock, Scissors, "p1"},
+		{"Scissors vs Lizard", Scissors, Lizard, "p1"},
+		{"Paper vs Lizard", Paper, Lizard, "p2"},
+		{"Rock vs Spock", Rock, Spock, "p2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RPSLS.Resolve(tt.p1, tt.p2)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.result {
+				t.Errorf("Expected %s, got %s", tt.result, result)
+			}
+		})
+	}
+
+	t.Run("Classic rejects lizard", func(t *testing.T) {
+		if _, err := Classic.Resolve(Lizard, Rock); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
+
+func TestNewRuleSetValidation(t *testing.T) {
+	t.Run("Even number of moves", func(t *testing.T) {
+		_, err := NewCyclicRuleSet("even", []Move{"a", "b", "c", "d"})
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Duplicate moves", func(t *testing.T) {
+		_, err := NewCyclicRuleSet("dup", []Move{"a", "b", "a"})
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Unbalanced graph", func(t *testing.T) {
+		_, err := NewRuleSet("unbalanced", []Move{"a", "b", "c"}, map[Move][]Move{
+			"a": {"b", "c"},
+			"b": {"c"},
+		})
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Pair beating each other", func(t *testing.T) {
+		_, err := NewRuleSet("mutual", []Move{"a", "b", "c"}, map[Move][]Move{
+			"a": {"b"},
+			"b": {"a", "c"},
+			"c": {"a"},
+		})
+		if err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Cyclic rule set with 101 moves", func(t *testing.T) {
+		moves := make([]Move, 101)
+		for i := range moves {
+			moves[i] = Move(string(rune('a'+i%26)) + string(rune('a'+i/26)))
+		}
+		rs, err := NewCyclicRuleSet("rps101", moves)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !rs.Beats(moves[0], moves[50]) || rs.Beats(moves[0], moves[51]) {
+			t.Errorf("Expected first move to beat the next 50 moves only")
+		}
+	})
+}
+
+func TestRegisterRuleSet(t *testing.T) {
+	rs, err := NewCyclicRuleSet("test-register", []Move{"x", "y", "z"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := RegisterRuleSet(rs); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := RegisterRuleSet(rs); err == nil {
+		t.Errorf("Expected error registering twice, got nil")
+	}
+
+	config := DefaultMatchConfig()
+	config.RuleSet = rs.Name
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestGameUsesRuleSet(t *testing.T) {
+	config := DefaultMatchConfig()
+	config.RuleSet = RPSLS.Name
+	game := NewGameWithConfig("game1", "Alice", "Bob", config)
+
+	if _, err := game.NewRound(); err != nil {
+		t.Fatalf("Expected no error creating new round, got %v", err)
+	}
+	if err := game.PlayRound(Spock, Rock); err != nil {
+		t.Fatalf("Expected no error playing round, got %v", err)
+	}
+	if game.P1Wins != 1 {
+		t.Errorf("Expected P1Wins to be 1, got %d", game.P1Wins)
+	}
+
+	config.RuleSet = "unknown"
+	if err := config.Validate(); err == nil {
+		t.Errorf("Expected error for unknown rule set, got nil")
+	}
+}
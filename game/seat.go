@@ -0,0 +1,68 @@
+package game
+
+import "errors"
+
+type Seat string
+
+const (
+	SeatP1        Seat = "p1"
+	SeatP2        Seat = "p2"
+	SeatSpectator Seat = "spectator"
+)
+
+var ErrGameFull = errors.New("game is full")
+
+// AddPlayer seats a player in the game. The first free seat goes to the
+// player and fills the game; a player who is already seated gets their seat
+// back, so rejoining is harmless. Anyone else gets ErrGameFull.
+func (g *Game) AddPlayer(player string) (Seat, error) {
+	defer g.flush()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if player == "" {
+		return "", errors.New("player is required")
+	}
+	if seat := g.seatOf(player); seat != SeatSpectator {
+		return seat, nil
+	}
+
+	if g.P1 != "" && g.P2 != "" {
+		return "", ErrGameFull
+	}
+	if err := g.transition(StateReady); err != nil {
+		return "", err
+	}
+
+	seat := SeatP2
+	if g.P1 == "" {
+		g.P1 = player
+		seat = SeatP1
+	} else {
+		g.P2 = player
+	}
+	g.LastActivity = g.clock.Now()
+
+	g.emit(PlayerJoined{EventMeta: g.metaAt(g.LastActivity), PlayerID: player, Seat: seat})
+	g.emit(GameReady{EventMeta: g.metaAt(g.LastActivity), P1: g.P1, P2: g.P2})
+	return seat, nil
+}
+
+func (g *Game) SeatOf(player string) Seat {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.seatOf(player)
+}
+
+func (g *Game) seatOf(player string) Seat {
+	switch {
+	case player == "":
+		return SeatSpectator
+	case player == g.P1:
+		return SeatP1
+	case player == g.P2:
+		return SeatP2
+	}
+	return SeatSpectator
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddPlayer(t *testing.T) {
+	t.Run("Second player takes P2", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		seat, err := game.AddPlayer("Bob")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if seat != SeatP2 || game.P2 != "Bob" {
+			t.Errorf("Expected 'Bob' in seat p2, got '%s' in %s", game.P2, seat)
+		}
+		if game.State != StateReady {
+			t.Errorf("Expected %s, got %s", StateReady, game.State)
+		}
+	})
+
+	t.Run("Rejoining is idempotent", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		seat, err := game.AddPlayer("Alice")
+		if err != nil || seat != SeatP1 {
+			t.Fatalf("Expected seat p1, got %s, %v", seat, err)
+		}
+		if game.State != StateWaitingForOpponent || game.P2 != "" {
+			t.Errorf("Expected game to still wait for an opponent")
+		}
+
+		game.AddPlayer("Bob")
+		seat, err = game.AddPlayer("Bob")
+		if err != nil || seat != SeatP2 {
+			t.Errorf("Expected seat p2, got %s, %v", seat, err)
+		}
+	})
+
+	t.Run("Third player is rejected", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		if _, err := game.AddPlayer("Charlie"); !errors.Is(err, ErrGameFull) {
+			t.Errorf("Expected ErrGameFull, got %v", err)
+		}
+		if game.SeatOf("Charlie") != SeatSpectator {
+			t.Errorf("Expected 'Charlie' to be a spectator")
+		}
+	})
+
+	t.Run("Empty player is rejected", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		if _, err := game.AddPlayer(""); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Joined game can be played", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		game.AddPlayer("Bob")
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		result, err := game.SubmitMove("Bob", Paper)
+		if err != nil || !result.Resolved {
+			t.Fatalf("Expected round to resolve, got %v", err)
+		}
+	})
+
+	t.Run("Emits joined and ready events", func(t *testing.T) {
+		bus := NewInMemoryEventBus()
+		events := collectEvents(bus)
+		m := NewManager(WithEventBus(bus))
+		game, _ := m.CreateGame("Alice", "", DefaultMatchConfig())
+		game.AddPlayer("Bob")
+		game.AddPlayer("Bob")
+
+		got := eventTypes(*events)
+		expected := []EventType{EventGameCreated, EventPlayerJoined, EventGameReady}
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+type State string
+
+const (
+	StateWaitingForOpponent State = "waiting_for_opponent"
+	StateReady              State = "ready"
+	StateRoundInProgress    State = "round_in_progress"
+	StateRoundResolved      State = "round_resolved"
+	StateFinished           State = "finished"
+	StateAbandoned          State = "abandoned"
+)
+
+// transitions lists the states each state may move to. A game finishes from
+// ready or mid-round when a player forfeits it by leaving.
+var transitions = map[State][]State{
+	StateWaitingForOpponent: {StateReady, StateAbandoned},
+	StateReady:              {StateRoundInProgress, StateFinished, StateAbandoned},
+	StateRoundInProgress:    {StateRoundResolved, StateFinished, StateAbandoned},
+	StateRoundResolved:      {StateRoundInProgress, StateFinished, StateAbandoned},
+	StateFinished:           {},
+	StateAbandoned:          {},
+}
+
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot go from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+func (s State) CanTransitionTo(to State) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s State) IsTerminal() bool {
+	return s == StateFinished || s == StateAbandoned
+}
+
+func (g *Game) transition(to State) error {
+	if !g.State.CanTransitionTo(to) {
+		return &TransitionError{From: g.State, To: to}
+	}
+	g.State = to
+	return nil
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStateTransitions(t *testing.T) {
+	tests := []struct {
+		from    State
+		to      State
+		allowed bool
+	}{
+		{StateWaitingForOpponent, StateReady, true},
+		{StateWaitingForOpponent, StateRoundInProgress, false},
+		{StateReady, StateRoundInProgress, true},
+		{StateRoundInProgress, StateRoundInProgress, false},
+		{StateRoundInProgress, StateRoundResolved, true},
+		{StateRoundResolved, StateRoundInProgress, true},
+		{StateRoundResolved, StateFinished, true},
+		{StateReady, StateFinished, true},
+		{StateRoundInProgress, StateFinished, true},
+		{StateWaitingForOpponent, StateFinished, false},
+		{StateFinished, StateRoundInProgress, false},
+		{StateAbandoned, StateReady, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.allowed {
+				t.Errorf("Expected %v, got %v", tt.allowed, got)
+			}
+		})
+	}
+}
+
+func TestGameState(t *testing.T) {
+	t.Run("New game states", func(t *testing.T) {
+		if game := NewGame("game1", "Alice", "Bob"); game.State != StateReady {
+			t.Errorf("Expected %s, got %s", StateReady, game.State)
+		}
+		if game := NewGame("game1", "Alice", ""); game.State != StateWaitingForOpponent {
+			t.Errorf("Expected %s, got %s", StateWaitingForOpponent, game.State)
+		}
+	})
+
+	t.Run("Cannot restart a round in progress", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		game.CurrentRound.P1 = Rock
+
+		_, err := game.NewRound()
+		var transitionErr *TransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Fatalf("Expected a TransitionError, got %v", err)
+		}
+		if transitionErr.From != StateRoundInProgress || transitionErr.To != StateRoundInProgress {
+			t.Errorf("Expected in progress to in progress, got %s to %s", transitionErr.From, transitionErr.To)
+		}
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected error to wrap ErrInvalidTransition")
+		}
+		if game.CurrentRound.P1 != Rock {
+			t.Errorf("Expected in-progress round to be kept")
+		}
+	})
+
+	t.Run("Cannot start a round without an opponent", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "")
+		if _, err := game.NewRound(); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("Expected ErrInvalidTransition, got %v", err)
+		}
+	})
+
+	t.Run("Rounds move between in progress and resolved", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		game.NewRound()
+		if game.State != StateRoundInProgress {
+			t.Errorf("Expected %s, got %s", StateRoundInProgress, game.State)
+		}
+		game.PlayRound(Rock, Scissors)
+		if game.State != StateRoundResolved {
+			t.Errorf("Expected %s, got %s", StateRoundResolved, game.State)
+		}
+		game.NewRound()
+		game.PlayRound(Rock, Scissors)
+		if game.State != StateFinished {
+			t.Errorf("Expected %s, got %s", StateFinished, game.State)
+		}
+	})
+
+	t.Run("Aborted game is abandoned", func(t *testing.T) {
+		game, clock := newTimedGame(TimeoutAbort)
+		round, _ := game.NewRound()
+		clock.Advance(round.Deadline.Sub(clock.Now()))
+		game.ExpireRound(round.Number)
+		if game.State != StateAbandoned {
+			t.Errorf("Expected %s, got %s", StateAbandoned, game.State)
+		}
+	})
+
+	t.Run("State is part of the view", func(t *testing.T) {
+		game := NewGame("game1", "Alice", "Bob")
+		if view := game.ViewFor("Alice"); view.State != StateReady {
+			t.Errorf("Expected %s, got %s", StateReady, view.State)
+		}
+	})
+}
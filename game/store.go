@@ -0,0 +1,272 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrGameNotFound = errors.New("game not found")
+
+// GameRecord is the persisted form of a game. Connection flags are left out
+// since nobody is connected to a game that was just loaded.
+type GameRecord struct {
+	ID           string      `json:"id"`
+	P1           string      `json:"p1"`
+	P2           string      `json:"p2"`
+	State        State       `json:"state"`
+	Config       MatchConfig `json:"config"`
+	Rounds       []Round     `json:"rounds"`
+	CurrentRound *Round      `json:"currentRound"`
+	P1Wins       int         `json:"p1Wins"`
+	P2Wins       int         `json:"p2Wins"`
+	Winner       string      `json:"winner"`
+	EndReason    string      `json:"endReason"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	LastActivity time.Time   `json:"lastActivity"`
+}
+
+// Store persists games. SaveGame stores everything but the finished rounds,
+// which are added one at a time with AppendRound; LoadGame returns both. The
+// event log is read back with LoadEvents.
+type Store interface {
+	SaveGame(record GameRecord) error
+	LoadGame(id string) (GameRecord, error)
+	ListGamesByPlayer(playerID string) ([]GameRecord, error)
+	AppendRound(gameID string, round Round) error
+	ListUnfinishedGames() ([]GameRecord, error)
+	// SaveEvent appends the event to its game's log and saves the record
+	// the event left the game in, along with the round it resolved if it is
+	// a RoundResolved, as a single write.
+	SaveEvent(record GameRecord, event Event) error
+	LoadEvents(gameID string) ([]Event, error)
+}
+
+// Record snapshots the game for storage.
+func (g *Game) Record() GameRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.record()
+}
+
+func (g *Game) record() GameRecord {
+	record := GameRecord{
+		ID:           g.ID,
+		P1:           g.P1,
+		P2:           g.P2,
+		State:        g.State,
+		Config:       g.Config,
+		Rounds:       slices.Clone(g.Rounds),
+		P1Wins:       g.P1Wins,
+		P2Wins:       g.P2Wins,
+		Winner:       g.Winner,
+		EndReason:    g.EndReason,
+		CreatedAt:    g.CreatedAt,
+		LastActivity: g.LastActivity,
+	}
+	if g.CurrentRound != nil {
+		round := *g.CurrentRound
+		record.CurrentRound = &round
+	}
+	return record
+}
+
+func restoreGame(record GameRecord, clock Clock) *Game {
+	g := newGame(record.ID, record.P1, record.P2, record.Config, clock)
+	g.State = record.State
+	g.Rounds = slices.Clone(record.Rounds)
+	if g.Rounds == nil {
+		g.Rounds = []Round{}
+	}
+	if record.CurrentRound != nil {
+		round := *record.CurrentRound
+		g.CurrentRound = &round
+	}
+	g.P1Wins = record.P1Wins
+	g.P2Wins = record.P2Wins
+	g.Winner = record.Winner
+	g.EndReason = record.EndReason
+	g.CreatedAt = record.CreatedAt
+	g.LastActivity = record.LastActivity
+	return g
+}
+
+// persist keeps the store in step with the manager's games. It runs as an
+// event subscriber, after the game's mutex has been released.
+func (gm *Manager) persist(event Event) {
+	game, exists := gm.GetGame(event.GameID())
+	if !exists {
+		return
+	}
+
+	if err := gm.store.SaveEvent(game.Record(), event); err != nil {
+		gm.storeErrors(event, err)
+	}
+}
+
+func logStoreError(event Event, err error) {
+	log.Printf("failed to store %s event of game %s: %v", event.Type(), event.GameID(), err)
+}
+
+// LoadUnfinishedGames puts every unfinished game in the store back under the
+// manager's control, for example after a restart, and returns them. Games
+// still waiting for an opponent stay in the store: with nobody to forfeit
+// to, nothing would ever end them.
+func (gm *Manager) LoadUnfinishedGames() ([]*Game, error) {
+	if gm.store == nil {
+		return nil, nil
+	}
+
+	records, err := gm.store.ListUnfinishedGames()
+	if err != nil {
+		return nil, err
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	games := make([]*Game, 0, len(records))
+	for _, record := range records {
+		if _, exists := gm.games[record.ID]; exists || record.State == StateWaitingForOpponent {
+			continue
+		}
+
+		game := restoreGame(record, gm.clock)
+		game.bus = gm.bus
+		if game.log, err = gm.store.LoadEvents(game.ID); err != nil {
+			log.Printf("failed to load events of game %s: %v", game.ID, err)
+		}
+		gm.games[game.ID] = game
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// EventLog returns the events of a game, from the manager if it still holds
+// the game and from the store otherwise.
+func (gm *Manager) EventLog(id string) ([]Event, error) {
+	if game, exists := gm.GetGame(id); exists {
+		return game.EventLog(), nil
+	}
+	if gm.store == nil {
+		return nil, ErrGameNotFound
+	}
+	if _, err := gm.store.LoadGame(id); err != nil {
+		return nil, err
+	}
+	return gm.store.LoadEvents(id)
+}
+
+// MemoryStore keeps games in memory. It is meant for tests.
+type MemoryStore struct {
+	games  map[string]GameRecord
+	rounds map[string][]Round
+	events map[string][]Event
+	mu     sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		games:  make(map[string]GameRecord),
+		rounds: make(map[string][]Round),
+		events: make(map[string][]Event),
+	}
+}
+
+func (s *MemoryStore) SaveGame(record GameRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Rounds = nil
+	s.games[record.ID] = record
+	return nil
+}
+
+func (s *MemoryStore) LoadGame(id string) (GameRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.load(id)
+}
+
+func (s *MemoryStore) load(id string) (GameRecord, error) {
+	record, exists := s.games[id]
+	if !exists {
+		return GameRecord{}, ErrGameNotFound
+	}
+	record.Rounds = slices.Clone(s.rounds[id])
+	return record, nil
+}
+
+func (s *MemoryStore) ListGamesByPlayer(playerID string) ([]GameRecord, error) {
+	return s.list(func(record GameRecord) bool {
+		return record.P1 == playerID || record.P2 == playerID
+	})
+}
+
+func (s *MemoryStore) ListUnfinishedGames() ([]GameRecord, error) {
+	return s.list(func(record GameRecord) bool {
+		return !record.State.IsTerminal()
+	})
+}
+
+func (s *MemoryStore) list(match func(GameRecord) bool) ([]GameRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []GameRecord
+	for id, record := range s.games {
+		if match(record) {
+			record, _ := s.load(id)
+			records = append(records, record)
+		}
+	}
+	slices.SortFunc(records, func(a, b GameRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return records, nil
+}
+
+// AppendRound stores a finished round. Storing the same round number again
+// replaces it.
+func (s *MemoryStore) AppendRound(gameID string, round Round) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appendRound(gameID, round)
+	return nil
+}
+
+func (s *MemoryStore) appendRound(gameID string, round Round) {
+	rounds := s.rounds[gameID]
+	for i, r := range rounds {
+		if r.Number == round.Number {
+			rounds[i] = round
+			return
+		}
+	}
+	s.rounds[gameID] = append(rounds, round)
+}
+
+func (s *MemoryStore) SaveEvent(record GameRecord, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events[record.ID] = append(s.events[record.ID], event)
+	if e, ok := event.(RoundResolved); ok {
+		s.appendRound(record.ID, e.Round)
+	}
+	record.Rounds = nil
+	s.games[record.ID] = record
+	return nil
+}
+
+func (s *MemoryStore) LoadEvents(gameID string) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.events[gameID]), nil
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("Save and load", func(t *testing.T) {
+		s := NewMemoryStore()
+		record := GameRecord{ID: "game1", P1: "Alice", P2: "Bob", State: StateReady, Config: DefaultMatchConfig()}
+		if err := s.SaveGame(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := s.AppendRound("game1", Round{Number: 1, P1: Rock, P2: Scissors, Winner: "p1"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		loaded, err := s.LoadGame("game1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if loaded.P1 != "Alice" || loaded.P2 != "Bob" {
+			t.Errorf("Unexpected players %s and %s", loaded.P1, loaded.P2)
+		}
+		if len(loaded.Rounds) != 1 || loaded.Rounds[0].Winner != "p1" {
+			t.Errorf("Expected the appended round, got %+v", loaded.Rounds)
+		}
+	})
+
+	t.Run("Missing game", func(t *testing.T) {
+		s := NewMemoryStore()
+		if _, err := s.LoadGame("missing"); !errors.Is(err, ErrGameNotFound) {
+			t.Fatalf("Expected ErrGameNotFound, got %v", err)
+		}
+	})
+
+	t.Run("List by player and unfinished", func(t *testing.T) {
+		s := NewMemoryStore()
+		s.SaveGame(GameRecord{ID: "game1", P1: "Alice", P2: "Bob", State: StateFinished})
+		s.SaveGame(GameRecord{ID: "game2", P1: "Bob", P2: "Carol", State: StateRoundInProgress})
+		s.SaveGame(GameRecord{ID: "game3", P1: "Carol", State: StateWaitingForOpponent})
+
+		bob, _ := s.ListGamesByPlayer("Bob")
+		if len(bob) != 2 {
+			t.Errorf("Expected 2 games for Bob, got %d", len(bob))
+		}
+
+		unfinished, _ := s.ListUnfinishedGames()
+		if len(unfinished) != 2 {
+			t.Errorf("Expected 2 unfinished games, got %d", len(unfinished))
+		}
+	})
+}
+
+// failingStore fails to save any event.
+type failingStore struct {
+	*MemoryStore
+}
+
+func (failingStore) SaveEvent(GameRecord, Event) error {
+	return errors.New("disk full")
+}
+
+func TestManagerStore(t *testing.T) {
+	t.Run("Games are saved as they change", func(t *testing.T) {
+		store := NewMemoryStore()
+		m := NewManager(WithStore(store), WithUUIDGenerator(NewMockUUIDGenerator([]string{"game1"})))
+
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		game.SubmitMove("Bob", Scissors)
+
+		record, err := store.LoadGame("game1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if record.State != StateRoundResolved || record.P1Wins != 1 {
+			t.Errorf("Expected a resolved round won by Alice, got state %s and %d wins", record.State, record.P1Wins)
+		}
+		if len(record.Rounds) != 1 || record.Rounds[0].P1 != Rock {
+			t.Errorf("Expected the played round to be stored, got %+v", record.Rounds)
+		}
+	})
+
+	t.Run("Unfinished games are rehydrated", func(t *testing.T) {
+		store := NewMemoryStore()
+		clock := NewMockClock(time.Unix(0, 0))
+		ids := NewMockUUIDGenerator([]string{"game1", "game2", "game3"})
+		m := NewManager(WithStore(store), WithClock(clock), WithUUIDGenerator(ids))
+
+		unfinished, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		unfinished.NewRound()
+		unfinished.SubmitMove("Alice", Paper)
+
+		config := DefaultMatchConfig()
+		config.Rounds = 1
+		finished, _ := m.CreateGame("Carol", "Dave", config)
+		finished.NewRound()
+		finished.SubmitMove("Carol", Rock)
+		finished.SubmitMove("Dave", Paper)
+
+		m.CreateGame("Erin", "", DefaultMatchConfig())
+
+		restarted := NewManager(WithStore(store), WithClock(clock))
+		games, err := restarted.LoadUnfinishedGames()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(games) != 1 || games[0].ID != "game1" {
+			t.Fatalf("Expected only game1 to be rehydrated, got %d games", len(games))
+		}
+
+		game, exists := restarted.GetGame("game1")
+		if !exists {
+			t.Fatal("Expected rehydrated game to be managed")
+		}
+		if _, exists := restarted.GetGame("game2"); exists {
+			t.Error("Expected finished game to stay in the store")
+		}
+		if _, exists := restarted.GetGame("game3"); exists {
+			t.Error("Expected a game waiting for an opponent to stay in the store")
+		}
+
+		// The rehydrated game carries on where it stopped.
+		result, err := game.SubmitMove("Bob", Rock)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Resolved || result.Round.Winner != "p1" {
+			t.Errorf("Expected p1 to win the resumed round, got %+v", result.Round)
+		}
+	})
+	t.Run("Failed writes are reported", func(t *testing.T) {
+		var failed []EventType
+		m := NewManager(WithStore(failingStore{NewMemoryStore()}), WithStoreErrors(func(event Event, err error) {
+			failed = append(failed, event.Type())
+		}))
+
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.NewRound()
+		if !slices.Equal(failed, []EventType{EventGameCreated, EventRoundStarted}) {
+			t.Errorf("Expected every event to be reported, got %v", failed)
+		}
+	})
+
+	t.Run("Event log outlives the game", func(t *testing.T) {
+		store := NewMemoryStore()
+		m := NewManager(WithStore(store), WithUUIDGenerator(NewMockUUIDGenerator([]string{"game1"})))
+
+		game, _ := m.CreateGame("Alice", "Bob", DefaultMatchConfig())
+		game.NewRound()
+		game.SubmitMove("Alice", Rock)
+		live := game.EventLog()
+
+		m.RemoveGame("game1")
+		stored, err := m.EventLog("game1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(eventTypes(stored), eventTypes(live)) {
+			t.Errorf("Expected stored events %v, got %v", eventTypes(live), eventTypes(stored))
+		}
+
+		if _, err := m.EventLog("missing"); !errors.Is(err, ErrGameNotFound) {
+			t.Errorf("Expected ErrGameNotFound, got %v", err)
+		}
+	})
+}
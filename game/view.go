@@ -0,0 +1,106 @@
+package game
+
+import "time"
+
+// GameView is the serializable projection of a game as seen by one
+// recipient. Moves in the current round are only visible to the player who
+// made them; everyone else just learns that a move was made.
+type GameView struct {
+	ID           string      `json:"id"`
+	P1           string      `json:"p1"`
+	P2           string      `json:"p2"`
+	State        State       `json:"state"`
+	Config       MatchConfig `json:"config"`
+	Rounds       []RoundView `json:"rounds"`
+	CurrentRound *RoundView  `json:"currentRound"`
+	P1Wins       int         `json:"p1Wins"`
+	P2Wins       int         `json:"p2Wins"`
+	Winner       string      `json:"winner"`
+	EndReason    string      `json:"endReason"`
+	P1Connected  bool        `json:"p1Connected"`
+	P2Connected  bool        `json:"p2Connected"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	LastActivity time.Time   `json:"lastActivity"`
+}
+
+type RoundView struct {
+	Number     int               `json:"number"`
+	Phase      RoundPhase        `json:"phase"`
+	P1         Move              `json:"p1,omitempty"`
+	P2         Move              `json:"p2,omitempty"`
+	P1Moved    bool              `json:"p1Moved"`
+	P2Moved    bool              `json:"p2Moved"`
+	Winner     string            `json:"winner,omitempty"`
+	Deadline   time.Time         `json:"deadline"`
+	TimedOut   bool              `json:"timedOut"`
+	Transcript []TranscriptEntry `json:"transcript,omitempty"`
+}
+
+// ViewFor returns the game as seen by the given player. Anyone who is not
+// seated in the game gets the spectator view.
+func (g *Game) ViewFor(playerID string) GameView {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.view(g.seatOf(playerID))
+}
+
+func (g *Game) SpectatorView() GameView {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.view(SeatSpectator)
+}
+
+func (g *Game) view(seat Seat) GameView {
+	rounds := make([]RoundView, len(g.Rounds))
+	for i, r := range g.Rounds {
+		rounds[i] = RoundView{
+			Number:     r.Number,
+			Phase:      r.Phase,
+			P1:         r.P1,
+			P2:         r.P2,
+			P1Moved:    r.P1 != "",
+			P2Moved:    r.P2 != "",
+			Winner:     r.Winner,
+			Deadline:   r.Deadline,
+			TimedOut:   r.TimedOut,
+			Transcript: r.Transcript,
+		}
+	}
+
+	var current *RoundView
+	if r := g.CurrentRound; r != nil {
+		current = &RoundView{
+			Number:   r.Number,
+			Phase:    r.Phase,
+			P1Moved:  r.P1 != "" || r.P1Commit != "",
+			P2Moved:  r.P2 != "" || r.P2Commit != "",
+			Deadline: r.Deadline,
+		}
+		switch seat {
+		case SeatP1:
+			current.P1 = r.P1
+		case SeatP2:
+			current.P2 = r.P2
+		}
+	}
+
+	return GameView{
+		ID:           g.ID,
+		P1:           g.P1,
+		P2:           g.P2,
+		State:        g.State,
+		Config:       g.Config,
+		Rounds:       rounds,
+		CurrentRound: current,
+		P1Wins:       g.P1Wins,
+		P2Wins:       g.P2Wins,
+		Winner:       g.Winner,
+		EndReason:    g.EndReason,
+		P1Connected:  g.P1Connected,
+		P2Connected:  g.P2Connected,
+		CreatedAt:    g.CreatedAt,
+		LastActivity: g.LastActivity,
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestViewFor(t *testing.T) {
+	game := NewGame("game1", "Alice", "Bob")
+	game.NewRound()
+	game.CurrentRound.P1 = Rock
+
+	t.Run("Player sees their own move", func(t *testing.T) {
+		view := game.ViewFor("Alice")
+		if view.CurrentRound.P1 != Rock {
+			t.Errorf("Expected own move to be visible, got '%s'", view.CurrentRound.P1)
+		}
+		if !view.CurrentRound.P1Moved || view.CurrentRound.P2Moved {
+			t.Errorf("Expected only P1 to have moved")
+		}
+	})
+
+	t.Run("Opponent does not see the pending move", func(t *testing.T) {
+		view := game.ViewFor("Bob")
+		if view.CurrentRound.P1 != "" {
+			t.Errorf("Expected opponent move to be hidden, got '%s'", view.CurrentRound.P1)
+		}
+		if !view.CurrentRound.P1Moved {
+			t.Errorf("Expected P1Moved to be true")
+		}
+
+		data, err := json.Marshal(view)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Contains(string(data), string(Rock)) {
+			t.Errorf("Expected serialized view not to mention the pending move: %s", data)
+		}
+	})
+
+	t.Run("Spectator sees no pending moves", func(t *testing.T) {
+		game.CurrentRound.P2 = Paper
+		for _, view := range []GameView{game.SpectatorView(), game.ViewFor("Eve"), game.ViewFor("")} {
+			if view.CurrentRound.P1 != "" || view.CurrentRound.P2 != "" {
+				t.Errorf("Expected both moves hidden, got '%s' and '%s'", view.CurrentRound.P1, view.CurrentRound.P2)
+			}
+		}
+		game.CurrentRound.P2 = ""
+	})
+
+	t.Run("Resolved rounds are public", func(t *testing.T) {
+		game.PlayRound(Rock, Scissors)
+		view := game.ViewFor("Bob")
+		if view.CurrentRound != nil {
+			t.Errorf("Expected no current round")
+		}
+		if len(view.Rounds) != 1 || view.Rounds[0].P1 != Rock || view.Rounds[0].P2 != Scissors {
+			t.Errorf("Expected resolved round moves to be visible, got %+v", view.Rounds)
+		}
+	})
+
+	t.Run("Empty seat is not matched by a spectator", func(t *testing.T) {
+		waiting := NewGame("game2", "Alice", "")
+		waiting.CurrentRound = &Round{Number: 1, P2: Rock}
+		if view := waiting.ViewFor(""); view.CurrentRound.P2 != "" {
+			t.Errorf("Expected move to be hidden, got '%s'", view.CurrentRound.P2)
+		}
+	})
+}
+
+func TestViewForCommitReveal(t *testing.T) {
+	game, _ := newCommitRevealGame(TimeoutForfeit)
+	game.NewRound()
+	game.CommitMove("Alice", HashMove(Rock, "n1"))
+
+	view := game.ViewFor("Bob")
+	if !view.CurrentRound.P1Moved {
+		t.Errorf("Expected commitment to count as a move")
+	}
+	if view.CurrentRound.Transcript != nil {
+		t.Errorf("Expected transcript of the pending round to be hidden")
+	}
+}
@@ -5,4 +5,7 @@ go 1.25.0
 require (
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
+	go.etcd.io/bbolt v1.4.0
 )
+
+require golang.org/x/sys v0.29.0 // indirect
@@ -0,0 +1,50 @@
+package lobby
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const (
+	CodeLength = 6
+	// codeAlphabet leaves out characters that are easy to confuse when read
+	// aloud or typed, such as 0/O and 1/I/L.
+	codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+type CodeGenerator interface {
+	Generate() string
+}
+
+type DefaultCodeGenerator struct{}
+
+func (g *DefaultCodeGenerator) Generate() string {
+	code := make([]byte, CodeLength)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code)
+}
+
+type MockCodeGenerator struct {
+	values []string
+	index  int
+}
+
+func NewMockCodeGenerator(values []string) *MockCodeGenerator {
+	return &MockCodeGenerator{values: values, index: 0}
+}
+
+func (g *MockCodeGenerator) Generate() string {
+	if g.index >= len(g.values) {
+		return ""
+	}
+	val := g.values[g.index]
+	g.index++
+	return val
+}
@@ -0,0 +1,278 @@
+// Package lobby implements private lobbies that players join with a short
+// invite code instead of going through matchmaking.
+package lobby
+
+import (
+	"errors"
+	"ldriko/rps-backend/game"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLobbyNotFound  = errors.New("lobby not found")
+	ErrLobbyFull      = errors.New("lobby is full")
+	ErrAlreadyInLobby = errors.New("already in a lobby")
+	ErrNotInLobby     = errors.New("not in a lobby")
+	ErrNotHost        = errors.New("only the host can do that")
+	ErrNoGuest        = errors.New("lobby has no guest")
+)
+
+// Lobby is a snapshot of a private lobby. The host controls the match
+// settings and decides when the game starts.
+type Lobby struct {
+	Code         string           `json:"code"`
+	Host         string           `json:"host"`
+	Guest        string           `json:"guest,omitempty"`
+	Config       game.MatchConfig `json:"config"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	LastActivity time.Time        `json:"lastActivity"`
+}
+
+type Manager struct {
+	lobbies       map[string]*Lobby
+	players       map[string]string
+	codeGenerator CodeGenerator
+	clock         game.Clock
+	mu            sync.RWMutex
+}
+
+type ManagerOption func(*Manager)
+
+func WithCodeGenerator(generator CodeGenerator) ManagerOption {
+	return func(lm *Manager) {
+		lm.codeGenerator = generator
+	}
+}
+
+func WithClock(clock game.Clock) ManagerOption {
+	return func(lm *Manager) {
+		lm.clock = clock
+	}
+}
+
+func NewManager(opts ...ManagerOption) *Manager {
+	lm := &Manager{
+		lobbies:       make(map[string]*Lobby),
+		players:       make(map[string]string),
+		codeGenerator: &DefaultCodeGenerator{},
+		clock:         game.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(lm)
+	}
+	return lm
+}
+
+// NormalizeCode makes codes case-insensitive and tolerant of stray spaces.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (lm *Manager) Create(host string, config game.MatchConfig) (Lobby, error) {
+	if err := config.Validate(); err != nil {
+		return Lobby{}, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if _, exists := lm.players[host]; exists {
+		return Lobby{}, ErrAlreadyInLobby
+	}
+
+	code := lm.codeGenerator.Generate()
+
+	maxRetries := 5
+	for i := 0; i < maxRetries; i++ {
+		if _, exists := lm.lobbies[code]; !exists && code != "" {
+			break
+		}
+		code = lm.codeGenerator.Generate()
+		if i == maxRetries-1 {
+			return Lobby{}, errors.New("failed to generate unique lobby code")
+		}
+	}
+
+	now := lm.clock.Now()
+	l := &Lobby{
+		Code:         code,
+		Host:         host,
+		Config:       config,
+		CreatedAt:    now,
+		LastActivity: now,
+	}
+	lm.lobbies[code] = l
+	lm.players[host] = code
+	return *l, nil
+}
+
+func (lm *Manager) Get(code string) (Lobby, bool) {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	l, exists := lm.lobbies[NormalizeCode(code)]
+	if !exists {
+		return Lobby{}, false
+	}
+	return *l, true
+}
+
+// LobbyOf returns the lobby the player is hosting or has joined.
+func (lm *Manager) LobbyOf(playerID string) (Lobby, bool) {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	l, exists := lm.lobbyOf(playerID)
+	if !exists {
+		return Lobby{}, false
+	}
+	return *l, true
+}
+
+func (lm *Manager) lobbyOf(playerID string) (*Lobby, bool) {
+	code, exists := lm.players[playerID]
+	if !exists {
+		return nil, false
+	}
+	l, exists := lm.lobbies[code]
+	return l, exists
+}
+
+func (lm *Manager) Join(code, playerID string) (Lobby, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	l, exists := lm.lobbies[NormalizeCode(code)]
+	if !exists {
+		return Lobby{}, ErrLobbyNotFound
+	}
+	if current, inLobby := lm.players[playerID]; inLobby {
+		if current == l.Code {
+			return *l, nil
+		}
+		return Lobby{}, ErrAlreadyInLobby
+	}
+	if l.Guest != "" {
+		return Lobby{}, ErrLobbyFull
+	}
+
+	l.Guest = playerID
+	l.LastActivity = lm.clock.Now()
+	lm.players[playerID] = l.Code
+	return *l, nil
+}
+
+// Leave takes the player out of their lobby. When the host leaves the lobby
+// is closed; closed reports whether that happened.
+func (lm *Manager) Leave(playerID string) (Lobby, bool, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	l, exists := lm.lobbyOf(playerID)
+	if !exists {
+		return Lobby{}, false, ErrNotInLobby
+	}
+
+	if l.Host == playerID {
+		lm.remove(l)
+		return *l, true, nil
+	}
+
+	l.Guest = ""
+	l.LastActivity = lm.clock.Now()
+	delete(lm.players, playerID)
+	return *l, false, nil
+}
+
+// Kick removes the guest from the host's lobby.
+func (lm *Manager) Kick(host, playerID string) (Lobby, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	l, err := lm.hostedBy(host)
+	if err != nil {
+		return Lobby{}, err
+	}
+	if l.Guest == "" || l.Guest != playerID {
+		return Lobby{}, ErrNotInLobby
+	}
+
+	l.Guest = ""
+	l.LastActivity = lm.clock.Now()
+	delete(lm.players, playerID)
+	return *l, nil
+}
+
+func (lm *Manager) SetConfig(host string, config game.MatchConfig) (Lobby, error) {
+	if err := config.Validate(); err != nil {
+		return Lobby{}, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	l, err := lm.hostedBy(host)
+	if err != nil {
+		return Lobby{}, err
+	}
+
+	l.Config = config
+	l.LastActivity = lm.clock.Now()
+	return *l, nil
+}
+
+// Start closes the host's lobby and returns it so the caller can create the
+// game between the host and the guest.
+func (lm *Manager) Start(host string) (Lobby, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	l, err := lm.hostedBy(host)
+	if err != nil {
+		return Lobby{}, err
+	}
+	if l.Guest == "" {
+		return Lobby{}, ErrNoGuest
+	}
+
+	lm.remove(l)
+	return *l, nil
+}
+
+func (lm *Manager) hostedBy(host string) (*Lobby, error) {
+	l, exists := lm.lobbyOf(host)
+	if !exists {
+		return nil, ErrNotInLobby
+	}
+	if l.Host != host {
+		return nil, ErrNotHost
+	}
+	return l, nil
+}
+
+func (lm *Manager) remove(l *Lobby) {
+	delete(lm.lobbies, l.Code)
+	delete(lm.players, l.Host)
+	if l.Guest != "" {
+		delete(lm.players, l.Guest)
+	}
+}
+
+// CleanupExpiredLobbies closes lobbies that have been idle for longer than
+// maxAge, freeing their codes, and returns them so members can be told.
+func (lm *Manager) CleanupExpiredLobbies(maxAge time.Duration) []Lobby {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	var expired []Lobby
+	now := lm.clock.Now()
+	for _, l := range lm.lobbies {
+		if now.Sub(l.LastActivity) > maxAge {
+			expired = append(expired, *l)
+			lm.remove(l)
+		}
+	}
+	return expired
+}
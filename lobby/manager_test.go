@@ -0,0 +1,247 @@
+package lobby
+
+import (
+	"errors"
+	"ldriko/rps-backend/game"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestManager(codes ...string) (*Manager, *game.MockClock) {
+	clock := game.NewMockClock(time.Unix(0, 0))
+	lm := NewManager(WithCodeGenerator(NewMockCodeGenerator(codes)), WithClock(clock))
+	return lm, clock
+}
+
+func TestDefaultCodeGenerator(t *testing.T) {
+	g := &DefaultCodeGenerator{}
+	for i := 0; i < 100; i++ {
+		code := g.Generate()
+		if len(code) != CodeLength {
+			t.Fatalf("Expected code of length %d, got %q", CodeLength, code)
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(codeAlphabet, c) {
+				t.Fatalf("Unexpected character %q in code %q", c, code)
+			}
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("Create lobby", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		l, err := lm.Create("host", game.DefaultMatchConfig())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Code != "ABC234" || l.Host != "host" {
+			t.Fatalf("Unexpected lobby %+v", l)
+		}
+	})
+
+	t.Run("Retries on collision", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234", "ABC234", "XYZ789")
+		lm.Create("host1", game.DefaultMatchConfig())
+
+		l, err := lm.Create("host2", game.DefaultMatchConfig())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Code != "XYZ789" {
+			t.Fatalf("Expected code XYZ789, got %s", l.Code)
+		}
+	})
+
+	t.Run("Fails after repeated collisions", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234", "ABC234", "ABC234", "ABC234", "ABC234", "ABC234", "ABC234")
+		lm.Create("host1", game.DefaultMatchConfig())
+
+		if _, err := lm.Create("host2", game.DefaultMatchConfig()); err == nil {
+			t.Fatal("Expected error for exhausted code generation")
+		}
+	})
+
+	t.Run("Host can only have one lobby", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234", "XYZ789")
+		lm.Create("host", game.DefaultMatchConfig())
+
+		if _, err := lm.Create("host", game.DefaultMatchConfig()); !errors.Is(err, ErrAlreadyInLobby) {
+			t.Fatalf("Expected ErrAlreadyInLobby, got %v", err)
+		}
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		config := game.DefaultMatchConfig()
+		config.Rounds = 0
+
+		if _, err := lm.Create("host", config); err == nil {
+			t.Fatal("Expected error for invalid config")
+		}
+	})
+}
+
+func TestJoin(t *testing.T) {
+	t.Run("Join by code is case-insensitive", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		lm.Create("host", game.DefaultMatchConfig())
+
+		l, err := lm.Join(" abc234 ", "guest")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Guest != "guest" {
+			t.Fatalf("Expected guest to be seated, got %q", l.Guest)
+		}
+	})
+
+	t.Run("Unknown code", func(t *testing.T) {
+		lm, _ := newTestManager()
+		if _, err := lm.Join("NOPE22", "guest"); !errors.Is(err, ErrLobbyNotFound) {
+			t.Fatalf("Expected ErrLobbyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Full lobby", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		lm.Create("host", game.DefaultMatchConfig())
+		lm.Join("ABC234", "guest")
+
+		if _, err := lm.Join("ABC234", "other"); !errors.Is(err, ErrLobbyFull) {
+			t.Fatalf("Expected ErrLobbyFull, got %v", err)
+		}
+	})
+
+	t.Run("Rejoining is idempotent", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		lm.Create("host", game.DefaultMatchConfig())
+		lm.Join("ABC234", "guest")
+
+		if _, err := lm.Join("ABC234", "guest"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestHostControls(t *testing.T) {
+	setup := func() *Manager {
+		lm, _ := newTestManager("ABC234")
+		lm.Create("host", game.DefaultMatchConfig())
+		lm.Join("ABC234", "guest")
+		return lm
+	}
+
+	t.Run("Kick guest", func(t *testing.T) {
+		lm := setup()
+		l, err := lm.Kick("host", "guest")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Guest != "" {
+			t.Fatalf("Expected empty guest seat, got %q", l.Guest)
+		}
+		if _, exists := lm.LobbyOf("guest"); exists {
+			t.Fatal("Expected kicked guest to leave the lobby")
+		}
+	})
+
+	t.Run("Guest cannot kick", func(t *testing.T) {
+		lm := setup()
+		if _, err := lm.Kick("guest", "host"); !errors.Is(err, ErrNotHost) {
+			t.Fatalf("Expected ErrNotHost, got %v", err)
+		}
+	})
+
+	t.Run("Set config", func(t *testing.T) {
+		lm := setup()
+		config, _ := game.ParseMatchFormat("first_to_3")
+
+		l, err := lm.SetConfig("host", config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Config.Format != game.FormatFirstTo || l.Config.Rounds != 3 {
+			t.Fatalf("Expected first_to_3, got %+v", l.Config)
+		}
+		if _, err := lm.SetConfig("guest", config); !errors.Is(err, ErrNotHost) {
+			t.Fatalf("Expected ErrNotHost, got %v", err)
+		}
+	})
+
+	t.Run("Start closes the lobby", func(t *testing.T) {
+		lm := setup()
+		l, err := lm.Start("host")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if l.Host != "host" || l.Guest != "guest" {
+			t.Fatalf("Unexpected lobby %+v", l)
+		}
+		if _, exists := lm.Get("ABC234"); exists {
+			t.Fatal("Expected lobby to be closed after starting")
+		}
+		if _, exists := lm.LobbyOf("guest"); exists {
+			t.Fatal("Expected guest to be free after starting")
+		}
+	})
+
+	t.Run("Start needs a guest", func(t *testing.T) {
+		lm, _ := newTestManager("ABC234")
+		lm.Create("host", game.DefaultMatchConfig())
+
+		if _, err := lm.Start("host"); !errors.Is(err, ErrNoGuest) {
+			t.Fatalf("Expected ErrNoGuest, got %v", err)
+		}
+	})
+
+	t.Run("Host leaving closes the lobby", func(t *testing.T) {
+		lm := setup()
+		_, closed, err := lm.Leave("host")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !closed {
+			t.Fatal("Expected lobby to be closed")
+		}
+		if _, exists := lm.LobbyOf("guest"); exists {
+			t.Fatal("Expected guest to be freed")
+		}
+	})
+
+	t.Run("Guest leaving keeps the lobby", func(t *testing.T) {
+		lm := setup()
+		l, closed, err := lm.Leave("guest")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if closed || l.Guest != "" {
+			t.Fatalf("Expected open lobby without guest, got %+v (closed=%v)", l, closed)
+		}
+	})
+}
+
+func TestCleanupExpiredLobbies(t *testing.T) {
+	lm, clock := newTestManager("ABC234", "XYZ789")
+	lm.Create("host1", game.DefaultMatchConfig())
+	clock.Advance(5 * time.Minute)
+	lm.Create("host2", game.DefaultMatchConfig())
+	clock.Advance(6 * time.Minute)
+
+	expired := lm.CleanupExpiredLobbies(10 * time.Minute)
+	if len(expired) != 1 || expired[0].Code != "ABC234" {
+		t.Fatalf("Expected lobby ABC234 to expire, got %+v", expired)
+	}
+	if _, exists := lm.Get("ABC234"); exists {
+		t.Fatal("Expected expired lobby to be removed")
+	}
+	if _, exists := lm.Get("XYZ789"); !exists {
+		t.Fatal("Expected active lobby to remain")
+	}
+
+	// The host of the expired lobby can create a new one.
+	if _, exists := lm.LobbyOf("host1"); exists {
+		t.Fatal("Expected host of expired lobby to be free")
+	}
+}
@@ -1,16 +1,80 @@
 package main
 
-import "ldriko/rps-backend/game"
+import (
+	"ldriko/rps-backend/auth"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/game/boltstore"
+	"ldriko/rps-backend/players"
+	"ldriko/rps-backend/server"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
 
 func main() {
-	result, _ := game.ResolveRound(game.Rock, game.Scissors)
-
-	switch result {
-	case "p1":
-		println("Player 1 wins!")
-	case "p2":
-		println("Player 2 wins!")
-	default:
-		println("It's a draw!")
+	addr := os.Getenv("ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "rps.db"
+	}
+
+	secret := []byte(os.Getenv("AUTH_SECRET"))
+	if len(secret) == 0 {
+		log.Printf("AUTH_SECRET is not set, sessions will not survive a restart")
+		secret = auth.RandomSecret()
+	}
+	tokens, err := auth.NewIssuer(secret)
+	if err != nil {
+		log.Fatalf("invalid AUTH_SECRET: %v", err)
+	}
+
+	config := server.DefaultConfig()
+	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
+		config.AllowedOrigins = strings.Split(origins, ",")
+	}
+	if grace := os.Getenv("DISCONNECT_GRACE_PERIOD"); grace != "" {
+		config.DisconnectGracePeriod, err = time.ParseDuration(grace)
+		if err != nil {
+			log.Fatalf("invalid DISCONNECT_GRACE_PERIOD: %v", err)
+		}
+	}
+
+	store, err := boltstore.Open(dbPath)
+	if err != nil {
+		log.Fatalf("failed to open %s: %v", dbPath, err)
+	}
+	defer store.Close()
+
+	s := server.NewServerWithManager(
+		game.NewManager(game.WithStore(store)),
+		server.WithPlayers(players.NewService(store.Players())),
+		server.WithTokens(tokens),
+		server.WithConfig(config),
+	)
+	if err := s.Restore(); err != nil {
+		log.Fatalf("failed to restore games: %v", err)
+	}
+
+	s.StartMatchmaking(server.DefaultMatchmakingConfig())
+	defer s.StopMatchmaking()
+	s.StartLobbySweeper(server.DefaultLobbySweepInterval, server.DefaultLobbyMaxAge)
+	defer s.StopLobbySweeper()
+
+	http.HandleFunc("/ws", s.HandleWebSocket)
+	http.HandleFunc("GET /games/{id}/replay", s.HandleReplay)
+	http.HandleFunc("POST /players", s.HandleRegister)
+	http.HandleFunc("POST /login", s.HandleLogin)
+	http.HandleFunc("POST /token/refresh", s.HandleRefresh)
+	http.HandleFunc("GET /players/{id}", s.HandleGetPlayer)
+	http.HandleFunc("PATCH /players/{id}", s.HandleUpdatePlayer)
+
+	log.Printf("listening on %s", addr)
+	if err := http.ListenAndServe(addr, nil); err != nil {
+		log.Fatal(err)
 	}
 }
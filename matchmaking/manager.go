@@ -1 +1,109 @@
 package matchmaking
+
+import (
+	"sync"
+	"time"
+)
+
+type MatchFunc func(match Match)
+
+// Matcher pairs up queued players in the background, calling onMatch for
+// every pair and onTick after each pass so callers can report queue status.
+type Matcher struct {
+	queue    *MatchmakingQueue
+	interval time.Duration
+	onMatch  MatchFunc
+	onTick   func()
+
+	backfillAfter time.Duration
+	onBackfill    func(QueuedPlayer)
+	maxWait       time.Duration
+	onTimeout     func(QueuedPlayer)
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type MatcherOption func(*Matcher)
+
+// WithBackfill hands players who found no opponent within after to
+// onBackfill, for example to pair them with a bot.
+func WithBackfill(after time.Duration, onBackfill func(QueuedPlayer)) MatcherOption {
+	return func(m *Matcher) {
+		m.backfillAfter = after
+		m.onBackfill = onBackfill
+	}
+}
+
+// WithQueueTimeout drops players who waited longer than maxWait and tells
+// onTimeout about each of them.
+func WithQueueTimeout(maxWait time.Duration, onTimeout func(QueuedPlayer)) MatcherOption {
+	return func(m *Matcher) {
+		m.maxWait = maxWait
+		m.onTimeout = onTimeout
+	}
+}
+
+func NewMatcher(queue *MatchmakingQueue, interval time.Duration, onMatch MatchFunc, onTick func(), opts ...MatcherOption) *Matcher {
+	m := &Matcher{
+		queue:    queue,
+		interval: interval,
+		onMatch:  onMatch,
+		onTick:   onTick,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *Matcher) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.RunOnce()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (m *Matcher) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// RunOnce matches as many pairs as the queue allows, then backfills or times
+// out whoever is left waiting too long. It returns how many matches were
+// made.
+func (m *Matcher) RunOnce() int {
+	matches := m.queue.TryMatchAll()
+	if m.onMatch != nil {
+		for _, match := range matches {
+			m.onMatch(match)
+		}
+	}
+
+	if m.onBackfill != nil {
+		for _, qp := range m.queue.TakeWaitingLongerThan(m.backfillAfter) {
+			m.onBackfill(qp)
+		}
+	}
+	if m.onTimeout != nil {
+		for _, qp := range m.queue.CleanupTimeoutQueuePlayers(m.maxWait) {
+			m.onTimeout(qp)
+		}
+	}
+
+	if m.onTick != nil {
+		m.onTick()
+	}
+	return len(matches)
+}
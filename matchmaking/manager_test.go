@@ -0,0 +1,84 @@
+package matchmaking
+
+import (
+	"fmt"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/rating"
+	"testing"
+	"time"
+)
+
+func TestMatcherRunOnce(t *testing.T) {
+	q := NewQueue()
+	for i := 1; i <= 5; i++ {
+		q.AddPlayer(&models.Player{ID: fmt.Sprintf("player%d", i)})
+	}
+
+	var pairs [][2]string
+	ticks := 0
+	m := NewMatcher(q, time.Second, func(match Match) {
+		pairs = append(pairs, [2]string{match.P1.ID, match.P2.ID})
+	}, func() {
+		ticks++
+	})
+
+	if matches := m.RunOnce(); matches != 2 {
+		t.Fatalf("Expected 2 matches, got %d", matches)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("Expected 2 pairs, got %d", len(pairs))
+	}
+	if ticks != 1 {
+		t.Fatalf("Expected 1 tick, got %d", ticks)
+	}
+	if pairs[0] != [2]string{"player1", "player2"} || pairs[1] != [2]string{"player3", "player4"} {
+		t.Fatalf("Expected players paired in join order, got %v", pairs)
+	}
+	if q.GetQueueSize() != 1 {
+		t.Fatalf("Expected 1 player left in queue, got %d", q.GetQueueSize())
+	}
+}
+
+func TestMatcherBackfill(t *testing.T) {
+	clock := newMockClock()
+	q := NewQueue(WithClock(clock))
+	q.AddPlayer(&models.Player{ID: "player1"})
+	clock.Advance(time.Minute)
+	q.AddPlayer(&models.Player{ID: "player2", Rating: rating.Rating{Rating: 3000}})
+
+	var backfilled []string
+	m := NewMatcher(q, time.Second, nil, nil, WithBackfill(30*time.Second, func(qp QueuedPlayer) {
+		backfilled = append(backfilled, qp.Player.ID)
+	}))
+	m.RunOnce()
+
+	if len(backfilled) != 1 || backfilled[0] != "player1" {
+		t.Fatalf("Expected player1 to be backfilled, got %v", backfilled)
+	}
+	if q.Contains("player1") {
+		t.Fatal("Expected backfilled player to leave the queue")
+	}
+	if !q.Contains("player2") {
+		t.Fatal("Expected player2 to keep waiting")
+	}
+}
+
+func TestMatcherQueueTimeout(t *testing.T) {
+	clock := newMockClock()
+	q := NewQueue(WithClock(clock))
+	q.AddPlayer(&models.Player{ID: "player1"})
+	clock.Advance(10 * time.Minute)
+
+	var timedOut []string
+	m := NewMatcher(q, time.Second, nil, nil, WithQueueTimeout(5*time.Minute, func(qp QueuedPlayer) {
+		timedOut = append(timedOut, qp.Player.ID)
+	}))
+	m.RunOnce()
+
+	if len(timedOut) != 1 || timedOut[0] != "player1" {
+		t.Fatalf("Expected player1 to time out, got %v", timedOut)
+	}
+	if q.GetQueueSize() != 0 {
+		t.Fatalf("Expected empty queue, got %d", q.GetQueueSize())
+	}
+}
@@ -1,45 +1,200 @@
 package matchmaking
 
 import (
+	"container/list"
+	"ldriko/rps-backend/game"
 	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/rating"
 	"log"
+	"math"
 	"sync"
 	"time"
 )
 
 type QueuedPlayer struct {
 	Player   *models.Player
+	Ticket   MatchTicket
 	JoinedAt time.Time
+
+	elem *list.Element
+}
+
+type Match struct {
+	P1       *models.Player
+	P2       *models.Player
+	P1Ticket MatchTicket
+	P2Ticket MatchTicket
+	// P1JoinedAt and P2JoinedAt are when the players joined the queue, for
+	// putting them back with Requeue if the match falls through.
+	P1JoinedAt time.Time
+	P2JoinedAt time.Time
+}
+
+// Settings combines both tickets into the settings of the game to create.
+func (m Match) Settings() MatchTicket {
+	return merge(m.P1Ticket, m.P2Ticket)
+}
+
+// RatingWindow limits how far apart two players' ratings can be for them to
+// be matched. The window starts at Base and widens by Growth for every second
+// a player has waited, up to Max.
+type RatingWindow struct {
+	Base   float64
+	Growth float64
+	Max    float64
+}
+
+func DefaultRatingWindow() RatingWindow {
+	return RatingWindow{
+		Base:   100,
+		Growth: 10,
+		Max:    1000,
+	}
+}
+
+func (w RatingWindow) At(waited time.Duration) float64 {
+	return math.Min(w.Base+w.Growth*waited.Seconds(), w.Max)
+}
+
+type QueueOption func(*MatchmakingQueue)
+
+// WithRatingWindow replaces the rating window of the default scorer.
+func WithRatingWindow(window RatingWindow) QueueOption {
+	return func(q *MatchmakingQueue) {
+		q.scorer = defaultScorer(window)
+	}
+}
+
+func WithScorer(scorer Scorer) QueueOption {
+	return func(q *MatchmakingQueue) {
+		q.scorer = scorer
+	}
+}
+
+// WithClock sets the clock players' waiting time is measured with.
+func WithClock(clock game.Clock) QueueOption {
+	return func(q *MatchmakingQueue) {
+		q.clock = clock
+	}
 }
 
+// MatchmakingQueue keeps players in the order they joined. The list gives
+// first-come-first-served matching and the map gives O(1) lookup and removal.
 type MatchmakingQueue struct {
 	players map[string]*QueuedPlayer
+	order   *list.List
+	scorer  Scorer
+	clock   game.Clock
+	// avgWait is a moving average of how long matched players waited.
+	avgWait time.Duration
 	mu      sync.RWMutex
 }
 
-func NewQueue() *MatchmakingQueue {
-	return &MatchmakingQueue{
+func NewQueue(opts ...QueueOption) *MatchmakingQueue {
+	q := &MatchmakingQueue{
 		players: make(map[string]*QueuedPlayer),
+		order:   list.New(),
+		scorer:  defaultScorer(DefaultRatingWindow()),
+		clock:   game.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(q)
 	}
+	return q
 }
 
+// AddPlayer queues the player with a ticket that accepts any opponent.
 func (q *MatchmakingQueue) AddPlayer(player *models.Player) {
+	q.AddTicket(MatchTicket{Player: player})
+}
+
+// AddTicket puts the ticket's player at the back of the queue. A player who
+// is already queued keeps their place and has their ticket replaced.
+func (q *MatchmakingQueue) AddTicket(ticket MatchTicket) {
+	ticket = withRating(ticket)
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.players[player.ID] = &QueuedPlayer{
-		Player:   player,
-		JoinedAt: time.Now(),
+	if qp, exists := q.players[ticket.Player.ID]; exists {
+		qp.Player = ticket.Player
+		qp.Ticket = ticket
+		return
+	}
+
+	qp := &QueuedPlayer{
+		Player:   ticket.Player,
+		Ticket:   ticket,
+		JoinedAt: q.clock.Now(),
 	}
+	qp.elem = q.order.PushBack(qp)
+	q.players[ticket.Player.ID] = qp
+}
+
+// Requeue puts a matched player back in the queue as if they had never left
+// it since joinedAt, for when their match falls through. They keep their
+// place ahead of everyone who joined later, along with the rating window and
+// backfill wait they built up. A player who queued again in the meantime
+// keeps their new ticket but gets the earlier place.
+func (q *MatchmakingQueue) Requeue(ticket MatchTicket, joinedAt time.Time) {
+	ticket = withRating(ticket)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if qp, exists := q.players[ticket.Player.ID]; exists {
+		if !qp.JoinedAt.After(joinedAt) {
+			return
+		}
+		ticket = qp.Ticket
+		q.remove(ticket.Player.ID)
+	}
+
+	qp := &QueuedPlayer{
+		Player:   ticket.Player,
+		Ticket:   ticket,
+		JoinedAt: joinedAt,
+	}
+	for e := q.order.Front(); e != nil; e = e.Next() {
+		if e.Value.(*QueuedPlayer).JoinedAt.After(joinedAt) {
+			qp.elem = q.order.InsertBefore(qp, e)
+			break
+		}
+	}
+	if qp.elem == nil {
+		qp.elem = q.order.PushBack(qp)
+	}
+	q.players[ticket.Player.ID] = qp
+}
+
+// withRating gives a player who has never been rated the default rating, so
+// they are matched as a newcomer rather than as the weakest possible player.
+func withRating(ticket MatchTicket) MatchTicket {
+	if ticket.Player.Rating.IsZero() {
+		player := *ticket.Player
+		player.Rating = rating.Default()
+		ticket.Player = &player
+	}
+	return ticket
 }
 
 func (q *MatchmakingQueue) RemovePlayer(playerID string) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	q.remove(playerID)
+}
+
+func (q *MatchmakingQueue) remove(playerID string) {
+	qp, exists := q.players[playerID]
+	if !exists {
+		return
+	}
+	q.order.Remove(qp.elem)
 	delete(q.players, playerID)
 }
 
+// TryMatch pairs the longest-waiting player who has an acceptable opponent.
 func (q *MatchmakingQueue) TryMatch() (*models.Player, *models.Player, bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -49,22 +204,144 @@ func (q *MatchmakingQueue) TryMatch() (*models.Player, *models.Player, bool) {
 		return nil, nil, false
 	}
 
-	var player1, player2 *QueuedPlayer
-	for _, p := range q.players {
-		if player1 == nil {
-			player1 = p
-		} else {
-			player2 = p
+	match, ok := q.matchNext(q.clock.Now())
+	if !ok {
+		return nil, nil, false
+	}
+	return match.P1, match.P2, true
+}
+
+// TryMatchAll pairs up every queued player it can in join order, leaving
+// only players without an acceptable opponent.
+func (q *MatchmakingQueue) TryMatchAll() []Match {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.clock.Now()
+	var matches []Match
+	for len(q.players) >= 2 {
+		match, ok := q.matchNext(now)
+		if !ok {
 			break
 		}
+		matches = append(matches, match)
 	}
+	return matches
+}
+
+// matchNext walks the queue in join order and pairs the first player that
+// has a compatible opponent with the best-scoring one, ties going to whoever
+// waited longest. It must be called with q.mu held.
+func (q *MatchmakingQueue) matchNext(now time.Time) (Match, bool) {
+	for e := q.order.Front(); e != nil; e = e.Next() {
+		player1 := e.Value.(*QueuedPlayer)
+
+		var player2 *QueuedPlayer
+		bestScore := math.Inf(1)
+		for o := e.Next(); o != nil; o = o.Next() {
+			candidate := o.Value.(*QueuedPlayer)
+			if !Compatible(player1.Ticket, candidate.Ticket) {
+				continue
+			}
+
+			score, ok := q.scorer(player1, candidate, now)
+			if ok && score < bestScore {
+				player2 = candidate
+				bestScore = score
+			}
+		}
+
+		if player2 != nil {
+			return q.match(player1, player2, now), true
+		}
+	}
+	return Match{}, false
+}
 
+func (q *MatchmakingQueue) match(player1, player2 *QueuedPlayer, now time.Time) Match {
 	log.Printf("matched players %s and %s", player1.Player.ID, player2.Player.ID)
 
-	delete(q.players, player1.Player.ID)
-	delete(q.players, player2.Player.ID)
+	q.recordWait(now.Sub(player1.JoinedAt))
+	q.recordWait(now.Sub(player2.JoinedAt))
+
+	q.remove(player1.Player.ID)
+	q.remove(player2.Player.ID)
+
+	return Match{
+		P1:         player1.Player,
+		P2:         player2.Player,
+		P1Ticket:   player1.Ticket,
+		P2Ticket:   player2.Ticket,
+		P1JoinedAt: player1.JoinedAt,
+		P2JoinedAt: player2.JoinedAt,
+	}
+}
+
+func (q *MatchmakingQueue) recordWait(wait time.Duration) {
+	if q.avgWait == 0 {
+		q.avgWait = wait
+		return
+	}
+	q.avgWait = (q.avgWait*4 + wait) / 5
+}
+
+func (q *MatchmakingQueue) Contains(playerID string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	_, exists := q.players[playerID]
+	return exists
+}
+
+// Position returns the 1-based place of the player in the queue.
+func (q *MatchmakingQueue) Position(playerID string) (int, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if _, exists := q.players[playerID]; !exists {
+		return 0, false
+	}
+
+	position := 1
+	for e := q.order.Front(); e != nil; e = e.Next() {
+		if e.Value.(*QueuedPlayer).Player.ID == playerID {
+			break
+		}
+		position++
+	}
+	return position, true
+}
+
+// EstimateWait guesses how much longer the player will wait, based on how
+// long recently matched players waited. It is zero until a match was made.
+func (q *MatchmakingQueue) EstimateWait(playerID string) time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
 
-	return player1.Player, player2.Player, true
+	qp, exists := q.players[playerID]
+	if !exists {
+		return 0
+	}
+
+	remaining := q.avgWait - time.Since(qp.JoinedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Snapshot returns copies of the queued players in queue order.
+func (q *MatchmakingQueue) Snapshot() []QueuedPlayer {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	players := make([]QueuedPlayer, 0, len(q.players))
+	for e := q.order.Front(); e != nil; e = e.Next() {
+		qp := *e.Value.(*QueuedPlayer)
+		qp.elem = nil
+		players = append(players, qp)
+	}
+	return players
 }
 
 func (q *MatchmakingQueue) GetQueueSize() int {
@@ -73,15 +350,43 @@ func (q *MatchmakingQueue) GetQueueSize() int {
 	return len(q.players)
 }
 
-func (q *MatchmakingQueue) CleanupTimeoutQueuePlayers(maxWait time.Duration) {
+// CleanupTimeoutQueuePlayers removes players who have waited longer than
+// maxWait and returns them so they can be told.
+func (q *MatchmakingQueue) CleanupTimeoutQueuePlayers(maxWait time.Duration) []QueuedPlayer {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	now := time.Now()
-	for id, qp := range q.players {
+	removed := q.removeWaitingLongerThan(maxWait)
+	for _, qp := range removed {
+		log.Printf("removing inactive player %s from queue", qp.Player.ID)
+	}
+	return removed
+}
+
+// TakeWaitingLongerThan removes and returns the players who have waited
+// longer than maxWait, oldest first.
+func (q *MatchmakingQueue) TakeWaitingLongerThan(maxWait time.Duration) []QueuedPlayer {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.removeWaitingLongerThan(maxWait)
+}
+
+func (q *MatchmakingQueue) removeWaitingLongerThan(maxWait time.Duration) []QueuedPlayer {
+	var removed []QueuedPlayer
+
+	now := q.clock.Now()
+	for e := q.order.Front(); e != nil; {
+		qp := e.Value.(*QueuedPlayer)
+		e = e.Next()
+
 		if now.Sub(qp.JoinedAt) > maxWait {
-			log.Printf("removing inactive player %s from queue", id)
-			delete(q.players, id)
+			q.remove(qp.Player.ID)
+
+			removedPlayer := *qp
+			removedPlayer.elem = nil
+			removed = append(removed, removedPlayer)
 		}
 	}
+	return removed
 }
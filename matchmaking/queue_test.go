@@ -2,11 +2,17 @@ package matchmaking
 
 import (
 	"fmt"
+	"ldriko/rps-backend/game"
 	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/rating"
 	"testing"
 	"time"
 )
 
+func newMockClock() *game.MockClock {
+	return game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+}
+
 func TestNewQueue(t *testing.T) {
 	q := NewQueue()
 	if q == nil {
@@ -30,6 +36,23 @@ func TestAddPlayer(t *testing.T) {
 	}
 }
 
+func TestUnratedPlayersGetTheDefaultRating(t *testing.T) {
+	q := NewQueue()
+	unrated := &models.Player{ID: "player1"}
+	q.AddPlayer(unrated)
+	q.AddPlayer(&models.Player{ID: "player2", Rating: rating.Default()})
+
+	if q.players["player1"].Player.Rating != rating.Default() {
+		t.Fatalf("Expected the default rating, got %+v", q.players["player1"].Player.Rating)
+	}
+	if !unrated.Rating.IsZero() {
+		t.Fatal("Expected the caller's player to be left alone")
+	}
+	if _, _, matched := q.TryMatch(); !matched {
+		t.Fatal("Expected an unrated player to match a newly rated one")
+	}
+}
+
 func TestRemovePlayer(t *testing.T) {
 	q := NewQueue()
 	player := &models.Player{ID: "player1", Username: "Alice"}
@@ -123,9 +146,8 @@ func TestTryMatch(t *testing.T) {
 		if p1 == nil || p2 == nil {
 			t.Fatal("Expected non-nil players, got nil")
 		}
-		if (p1.ID != player1.ID && p1.ID != player2.ID && p1.ID != player3.ID) ||
-			(p2.ID != player1.ID && p2.ID != player2.ID && p2.ID != player3.ID) {
-			t.Fatal("Matched players do not match the added players")
+		if p1.ID != player1.ID || p2.ID != player2.ID {
+			t.Fatalf("Expected the two longest-waiting players to match, got %s and %s", p1.ID, p2.ID)
 		}
 		if p1.ID == p2.ID {
 			t.Fatal("Matched players should be different")
@@ -133,6 +155,9 @@ func TestTryMatch(t *testing.T) {
 		if len(q.players) != 1 {
 			t.Fatalf("Expected 1 player left in queue, got %d", len(q.players))
 		}
+		if _, exists := q.players[player3.ID]; !exists {
+			t.Fatal("Expected player3 to still be waiting")
+		}
 	})
 
 	t.Run("Match after removing a player", func(t *testing.T) {
@@ -169,8 +194,8 @@ func TestTryMatch(t *testing.T) {
 		if p1 == nil || p2 == nil {
 			t.Fatal("Expected non-nil players, got nil")
 		}
-		if (p1.ID != player2.ID && p1.ID != player3.ID) || (p2.ID != player2.ID && p2.ID != player3.ID) {
-			t.Fatal("Matched players do not match the added players")
+		if p1.ID != player2.ID || p2.ID != player3.ID {
+			t.Fatalf("Expected player2 and player3 to match, got %s and %s", p1.ID, p2.ID)
 		}
 		if p1.ID == p2.ID {
 			t.Fatal("Matched players should be different")
@@ -181,6 +206,98 @@ func TestTryMatch(t *testing.T) {
 	})
 }
 
+func TestTryMatchAll(t *testing.T) {
+	t.Run("Pairs players in join order", func(t *testing.T) {
+		q := NewQueue()
+		for i := 1; i <= 5; i++ {
+			q.AddPlayer(&models.Player{ID: fmt.Sprintf("player%d", i)})
+		}
+
+		matches := q.TryMatchAll()
+		if len(matches) != 2 {
+			t.Fatalf("Expected 2 matches, got %d", len(matches))
+		}
+
+		expected := []Match{
+			{P1: &models.Player{ID: "player1"}, P2: &models.Player{ID: "player2"}},
+			{P1: &models.Player{ID: "player3"}, P2: &models.Player{ID: "player4"}},
+		}
+		for i, match := range matches {
+			if match.P1.ID != expected[i].P1.ID || match.P2.ID != expected[i].P2.ID {
+				t.Fatalf("Expected match %d to be %s vs %s, got %s vs %s",
+					i, expected[i].P1.ID, expected[i].P2.ID, match.P1.ID, match.P2.ID)
+			}
+		}
+
+		if position, ok := q.Position("player5"); !ok || position != 1 {
+			t.Fatalf("Expected player5 alone at the front of the queue, got %d", position)
+		}
+	})
+
+	t.Run("Removed players are skipped", func(t *testing.T) {
+		q := NewQueue()
+		for i := 1; i <= 4; i++ {
+			q.AddPlayer(&models.Player{ID: fmt.Sprintf("player%d", i)})
+		}
+		q.RemovePlayer("player2")
+
+		matches := q.TryMatchAll()
+		if len(matches) != 1 {
+			t.Fatalf("Expected 1 match, got %d", len(matches))
+		}
+		if matches[0].P1.ID != "player1" || matches[0].P2.ID != "player3" {
+			t.Fatalf("Expected player1 vs player3, got %s vs %s", matches[0].P1.ID, matches[0].P2.ID)
+		}
+	})
+
+	t.Run("Rejoining keeps the original place", func(t *testing.T) {
+		q := NewQueue()
+		q.AddPlayer(&models.Player{ID: "player1"})
+		q.AddPlayer(&models.Player{ID: "player2"})
+		q.AddPlayer(&models.Player{ID: "player1"})
+
+		if q.GetQueueSize() != 2 {
+			t.Fatalf("Expected queue size 2, got %d", q.GetQueueSize())
+		}
+		if position, _ := q.Position("player1"); position != 1 {
+			t.Fatalf("Expected player1 to stay at position 1, got %d", position)
+		}
+	})
+
+	t.Run("Requeued players keep their place", func(t *testing.T) {
+		q := NewQueue()
+		for i := 1; i <= 3; i++ {
+			q.AddPlayer(&models.Player{ID: fmt.Sprintf("player%d", i)})
+		}
+		matches := q.TryMatchAll()
+		q.AddPlayer(&models.Player{ID: "player4"})
+
+		match := matches[0]
+		q.Requeue(match.P2Ticket, match.P2JoinedAt)
+		if position, _ := q.Position("player2"); position != 1 {
+			t.Fatalf("Expected player2 back at position 1, got %d", position)
+		}
+		if joinedAt := q.players["player2"].JoinedAt; !joinedAt.Equal(match.P2JoinedAt) {
+			t.Fatalf("Expected player2 to keep waiting since %v, got %v", match.P2JoinedAt, joinedAt)
+		}
+
+		q.Requeue(match.P1Ticket, match.P1JoinedAt)
+		if position, _ := q.Position("player1"); position != 1 {
+			t.Fatalf("Expected player1 back at position 1, got %d", position)
+		}
+		if position, _ := q.Position("player4"); position != 4 {
+			t.Fatalf("Expected player4 to stay last, got %d", position)
+		}
+	})
+
+	t.Run("Empty queue", func(t *testing.T) {
+		q := NewQueue()
+		if matches := q.TryMatchAll(); len(matches) != 0 {
+			t.Fatalf("Expected no matches, got %d", len(matches))
+		}
+	})
+}
+
 func TestConcurrentAddRemove(t *testing.T) {
 	q := NewQueue()
 	playerCount := 100
@@ -249,20 +366,22 @@ func TestGetQueueSize(t *testing.T) {
 }
 
 func TestCleanupTimeoutQueuePlayers(t *testing.T) {
-	q := NewQueue()
+	clock := newMockClock()
+	q := NewQueue(WithClock(clock))
 	player1 := &models.Player{ID: "player1", Username: "Alice"}
 	player2 := &models.Player{ID: "player2", Username: "Bob"}
 	q.AddPlayer(player1)
+	clock.Advance(5 * time.Minute)
 	q.AddPlayer(player2)
-
-	// Simulate timeout by adjusting JoinedAt
-	q.players[player1.ID].JoinedAt = time.Now().Add(-10 * time.Minute)
-	q.players[player2.ID].JoinedAt = time.Now().Add(-5 * time.Minute)
+	clock.Advance(5 * time.Minute)
 
 	// Cleanup players who have been in queue for more than 6 minutes
 	timeout := 6 * time.Minute
 
-	q.CleanupTimeoutQueuePlayers(timeout)
+	removed := q.CleanupTimeoutQueuePlayers(timeout)
+	if len(removed) != 1 || removed[0].Player.ID != player1.ID {
+		t.Fatalf("Expected player1 to be returned as removed, got %v", removed)
+	}
 
 	if size := q.GetQueueSize(); size != 1 {
 		t.Fatalf("Expected queue size 1 after cleanup, got %d", size)
@@ -274,3 +393,116 @@ func TestCleanupTimeoutQueuePlayers(t *testing.T) {
 		t.Fatalf("Expected player2 to remain in queue after cleanup")
 	}
 }
+
+func TestPosition(t *testing.T) {
+	q := NewQueue()
+	q.AddPlayer(&models.Player{ID: "player1"})
+	q.AddPlayer(&models.Player{ID: "player2"})
+	q.AddPlayer(&models.Player{ID: "player3"})
+
+	for i, id := range []string{"player1", "player2", "player3"} {
+		position, ok := q.Position(id)
+		if !ok {
+			t.Fatalf("Expected %s to be in queue", id)
+		}
+		if position != i+1 {
+			t.Fatalf("Expected %s at position %d, got %d", id, i+1, position)
+		}
+	}
+
+	if _, ok := q.Position("missing"); ok {
+		t.Fatal("Expected missing player to have no position")
+	}
+
+	snapshot := q.Snapshot()
+	if len(snapshot) != 3 || snapshot[0].Player.ID != "player1" || snapshot[2].Player.ID != "player3" {
+		t.Fatalf("Expected snapshot ordered by join time, got %v", snapshot)
+	}
+
+	q.RemovePlayer("player1")
+	if position, _ := q.Position("player3"); position != 2 {
+		t.Fatalf("Expected player3 to move up to position 2, got %d", position)
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	q := NewQueue()
+	q.AddPlayer(&models.Player{ID: "player1"})
+
+	if wait := q.EstimateWait("player1"); wait != 0 {
+		t.Fatalf("Expected no estimate before any match, got %v", wait)
+	}
+
+	q.avgWait = time.Minute
+	wait := q.EstimateWait("player1")
+	if wait <= 0 || wait > time.Minute {
+		t.Fatalf("Expected estimate within a minute, got %v", wait)
+	}
+}
+
+func TestRatingWindow(t *testing.T) {
+	rated := func(id string, r float64) *models.Player {
+		return &models.Player{ID: id, Rating: rating.Rating{Rating: r}}
+	}
+
+	t.Run("Prefers the closest rating within the window", func(t *testing.T) {
+		q := NewQueue()
+		q.AddPlayer(rated("player1", 1500))
+		q.AddPlayer(rated("player2", 1580))
+		q.AddPlayer(rated("player3", 1520))
+
+		p1, p2, matched := q.TryMatch()
+		if !matched {
+			t.Fatal("Expected a match, but got none")
+		}
+		if p1.ID != "player1" || p2.ID != "player3" {
+			t.Fatalf("Expected player1 vs player3, got %s vs %s", p1.ID, p2.ID)
+		}
+	})
+
+	t.Run("Skips players outside the window", func(t *testing.T) {
+		q := NewQueue()
+		q.AddPlayer(rated("player1", 1000))
+		q.AddPlayer(rated("player2", 1800))
+		q.AddPlayer(rated("player3", 1850))
+
+		matches := q.TryMatchAll()
+		if len(matches) != 1 {
+			t.Fatalf("Expected 1 match, got %d", len(matches))
+		}
+		if matches[0].P1.ID != "player2" || matches[0].P2.ID != "player3" {
+			t.Fatalf("Expected player2 vs player3, got %s vs %s", matches[0].P1.ID, matches[0].P2.ID)
+		}
+		if !q.Contains("player1") {
+			t.Fatal("Expected player1 to keep waiting")
+		}
+	})
+
+	t.Run("Window widens with waiting time", func(t *testing.T) {
+		clock := newMockClock()
+		q := NewQueue(WithRatingWindow(RatingWindow{Base: 100, Growth: 10, Max: 1000}), WithClock(clock))
+		q.AddPlayer(rated("player1", 1500))
+		q.AddPlayer(rated("player2", 1800))
+
+		if _, _, matched := q.TryMatch(); matched {
+			t.Fatal("Expected no match before the window widens")
+		}
+
+		clock.Advance(30 * time.Second)
+
+		p1, p2, matched := q.TryMatch()
+		if !matched {
+			t.Fatal("Expected a match once the window widened")
+		}
+		if p1.ID != "player1" || p2.ID != "player2" {
+			t.Fatalf("Expected player1 vs player2, got %s vs %s", p1.ID, p2.ID)
+		}
+	})
+
+	t.Run("Window is capped", func(t *testing.T) {
+		window := RatingWindow{Base: 100, Growth: 10, Max: 200}
+		if w := window.At(time.Hour); w != 200 {
+			t.Fatalf("Expected window capped at 200, got %.0f", w)
+		}
+	})
+}
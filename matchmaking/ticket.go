@@ -0,0 +1,120 @@
+package matchmaking
+
+import (
+	"ldriko/rps-backend/game/models"
+	"math"
+	"slices"
+	"time"
+)
+
+// DefaultRegionFallback is how long players wait for someone in their
+// preferred region before being matched across regions.
+const DefaultRegionFallback = 30 * time.Second
+
+// MatchTicket is a request to be matched, carrying the constraints an
+// opponent has to satisfy. Empty strings mean "any".
+type MatchTicket struct {
+	Player *models.Player
+	// Region is preferred rather than required; see RegionScorer.
+	Region string
+	// Mode is a match format such as "best_of_3".
+	Mode    string
+	RuleSet string
+	Ranked  bool
+	// Blocked lists player IDs this player never wants to be matched with.
+	Blocked []string
+	// PartySize is the number of players queueing together. Zero means one.
+	PartySize int
+}
+
+func (t MatchTicket) partySize() int {
+	if t.PartySize <= 0 {
+		return 1
+	}
+	return t.PartySize
+}
+
+func (t MatchTicket) blocks(playerID string) bool {
+	return slices.Contains(t.Blocked, playerID)
+}
+
+// Compatible reports whether two tickets may ever be matched, no matter how
+// long they wait.
+func Compatible(a, b MatchTicket) bool {
+	if a.Player.ID == b.Player.ID {
+		return false
+	}
+	if a.blocks(b.Player.ID) || b.blocks(a.Player.ID) {
+		return false
+	}
+	if a.Ranked != b.Ranked || a.partySize() != b.partySize() {
+		return false
+	}
+	return anyOrEqual(a.Mode, b.Mode) && anyOrEqual(a.RuleSet, b.RuleSet)
+}
+
+func anyOrEqual(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// merge combines two compatible tickets into the settings of their game.
+func merge(a, b MatchTicket) MatchTicket {
+	merged := a
+	if merged.Mode == "" {
+		merged.Mode = b.Mode
+	}
+	if merged.RuleSet == "" {
+		merged.RuleSet = b.RuleSet
+	}
+	if merged.Region == "" {
+		merged.Region = b.Region
+	}
+	merged.Player = nil
+	merged.Blocked = nil
+	return merged
+}
+
+// Scorer rates a pairing of two compatible players. Lower scores are better
+// matches; ok is false when the pair should not be matched yet.
+type Scorer func(a, b *QueuedPlayer, now time.Time) (score float64, ok bool)
+
+// CombineScorers adds up the scores of every scorer and only accepts a pair
+// all of them accept.
+func CombineScorers(scorers ...Scorer) Scorer {
+	return func(a, b *QueuedPlayer, now time.Time) (float64, bool) {
+		total := 0.0
+		for _, scorer := range scorers {
+			score, ok := scorer(a, b, now)
+			if !ok {
+				return 0, false
+			}
+			total += score
+		}
+		return total, true
+	}
+}
+
+// RatingScorer scores a pair by their rating gap and rejects gaps wider than
+// the rating window of whoever has waited longer.
+func RatingScorer(window RatingWindow) Scorer {
+	return func(a, b *QueuedPlayer, now time.Time) (float64, bool) {
+		gap := math.Abs(a.Player.Rating.Rating - b.Player.Rating.Rating)
+		limit := math.Max(window.At(now.Sub(a.JoinedAt)), window.At(now.Sub(b.JoinedAt)))
+		return gap, gap <= limit
+	}
+}
+
+// RegionScorer keeps players within their preferred region until both have
+// waited longer than fallback.
+func RegionScorer(fallback time.Duration) Scorer {
+	return func(a, b *QueuedPlayer, now time.Time) (float64, bool) {
+		if anyOrEqual(a.Ticket.Region, b.Ticket.Region) {
+			return 0, true
+		}
+		return 0, now.Sub(a.JoinedAt) >= fallback && now.Sub(b.JoinedAt) >= fallback
+	}
+}
+
+func defaultScorer(window RatingWindow) Scorer {
+	return CombineScorers(RatingScorer(window), RegionScorer(DefaultRegionFallback))
+}
@@ -0,0 +1,118 @@
+package matchmaking
+
+import (
+	"ldriko/rps-backend/game/models"
+	"testing"
+	"time"
+)
+
+func ticket(id string) MatchTicket {
+	return MatchTicket{Player: &models.Player{ID: id}}
+}
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(a, b *MatchTicket)
+		expected bool
+	}{
+		{"Default tickets", func(a, b *MatchTicket) {}, true},
+		{"Same mode", func(a, b *MatchTicket) { a.Mode, b.Mode = "best_of_5", "best_of_5" }, true},
+		{"Different modes", func(a, b *MatchTicket) { a.Mode, b.Mode = "best_of_5", "first_to_3" }, false},
+		{"Any mode", func(a, b *MatchTicket) { a.Mode = "best_of_5" }, true},
+		{"Different rule sets", func(a, b *MatchTicket) { a.RuleSet, b.RuleSet = "classic", "rpsls" }, false},
+		{"Ranked and casual", func(a, b *MatchTicket) { a.Ranked = true }, false},
+		{"Blocked by first", func(a, b *MatchTicket) { a.Blocked = []string{"player2"} }, false},
+		{"Blocked by second", func(a, b *MatchTicket) { b.Blocked = []string{"player1"} }, false},
+		{"Different party sizes", func(a, b *MatchTicket) { a.PartySize = 2 }, false},
+		{"Party of one", func(a, b *MatchTicket) { a.PartySize = 1 }, true},
+		{"Different regions", func(a, b *MatchTicket) { a.Region, b.Region = "eu", "us" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := ticket("player1"), ticket("player2")
+			tt.modify(&a, &b)
+
+			if got := Compatible(a, b); got != tt.expected {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAddTicket(t *testing.T) {
+	t.Run("Only compatible tickets are matched", func(t *testing.T) {
+		q := NewQueue()
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player1"}, Mode: "best_of_5"})
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player2"}, Mode: "first_to_3"})
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player3"}, Mode: "first_to_3"})
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player4"}})
+
+		matches := q.TryMatchAll()
+		if len(matches) != 2 {
+			t.Fatalf("Expected 2 matches, got %d", len(matches))
+		}
+		if matches[0].P1.ID != "player1" || matches[0].P2.ID != "player4" {
+			t.Fatalf("Expected player1 vs player4, got %s vs %s", matches[0].P1.ID, matches[0].P2.ID)
+		}
+		if matches[0].Settings().Mode != "best_of_5" {
+			t.Fatalf("Expected merged mode best_of_5, got %q", matches[0].Settings().Mode)
+		}
+		if matches[1].P1.ID != "player2" || matches[1].P2.ID != "player3" {
+			t.Fatalf("Expected player2 vs player3, got %s vs %s", matches[1].P1.ID, matches[1].P2.ID)
+		}
+	})
+
+	t.Run("Regions fall back after waiting", func(t *testing.T) {
+		clock := newMockClock()
+		q := NewQueue(WithClock(clock))
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player1"}, Region: "eu"})
+		q.AddTicket(MatchTicket{Player: &models.Player{ID: "player2"}, Region: "us"})
+
+		if _, _, matched := q.TryMatch(); matched {
+			t.Fatal("Expected no cross-region match right away")
+		}
+
+		clock.Advance(DefaultRegionFallback)
+		if _, _, matched := q.TryMatch(); !matched {
+			t.Fatal("Expected a cross-region match after the fallback")
+		}
+	})
+
+	t.Run("Custom scorer", func(t *testing.T) {
+		// Prefer the opponent with the longest username.
+		scorer := func(a, b *QueuedPlayer, now time.Time) (float64, bool) {
+			return -float64(len(b.Player.Username)), true
+		}
+		q := NewQueue(WithScorer(scorer))
+		q.AddPlayer(&models.Player{ID: "player1"})
+		q.AddPlayer(&models.Player{ID: "player2", Username: "Bob"})
+		q.AddPlayer(&models.Player{ID: "player3", Username: "Charlie"})
+
+		p1, p2, matched := q.TryMatch()
+		if !matched {
+			t.Fatal("Expected a match, but got none")
+		}
+		if p1.ID != "player1" || p2.ID != "player3" {
+			t.Fatalf("Expected player1 vs player3, got %s vs %s", p1.ID, p2.ID)
+		}
+	})
+}
+
+func TestCombineScorers(t *testing.T) {
+	accept := func(score float64) Scorer {
+		return func(a, b *QueuedPlayer, now time.Time) (float64, bool) { return score, true }
+	}
+	reject := func(a, b *QueuedPlayer, now time.Time) (float64, bool) { return 0, false }
+
+	a := &QueuedPlayer{Player: &models.Player{ID: "player1"}}
+	b := &QueuedPlayer{Player: &models.Player{ID: "player2"}}
+
+	if score, ok := CombineScorers(accept(1), accept(2))(a, b, time.Now()); !ok || score != 3 {
+		t.Fatalf("Expected score 3, got %v (ok=%v)", score, ok)
+	}
+	if _, ok := CombineScorers(accept(1), reject)(a, b, time.Now()); ok {
+		t.Fatal("Expected the pair to be rejected")
+	}
+}
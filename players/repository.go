@@ -0,0 +1,103 @@
+// Package players registers players and keeps their profiles.
+package players
+
+import (
+	"errors"
+	"ldriko/rps-backend/game/models"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrUsernameTaken  = errors.New("username is taken")
+)
+
+// Repository stores players. Usernames are unique regardless of case:
+// Create and Update fail with ErrUsernameTaken when another player already
+// has the username.
+type Repository interface {
+	Create(player models.Player) error
+	Get(id string) (models.Player, error)
+	GetByUsername(username string) (models.Player, error)
+	Update(player models.Player) error
+}
+
+// UsernameKey is the form of a username that uniqueness is checked on.
+func UsernameKey(username string) string {
+	return strings.ToLower(username)
+}
+
+// MemoryRepository keeps players in memory.
+type MemoryRepository struct {
+	players   map[string]models.Player
+	usernames map[string]string
+	mu        sync.RWMutex
+}
+
+var _ Repository = (*MemoryRepository)(nil)
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		players:   make(map[string]models.Player),
+		usernames: make(map[string]string),
+	}
+}
+
+func (r *MemoryRepository) Create(player models.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.players[player.ID]; exists {
+		return errors.New("player already exists")
+	}
+	if _, taken := r.usernames[UsernameKey(player.Username)]; taken {
+		return ErrUsernameTaken
+	}
+
+	r.players[player.ID] = player
+	r.usernames[UsernameKey(player.Username)] = player.ID
+	return nil
+}
+
+func (r *MemoryRepository) Get(id string) (models.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	player, exists := r.players[id]
+	if !exists {
+		return models.Player{}, ErrPlayerNotFound
+	}
+	return player, nil
+}
+
+func (r *MemoryRepository) GetByUsername(username string) (models.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.usernames[UsernameKey(username)]
+	if !exists {
+		return models.Player{}, ErrPlayerNotFound
+	}
+	return r.players[id], nil
+}
+
+func (r *MemoryRepository) Update(player models.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, exists := r.players[player.ID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+
+	key := UsernameKey(player.Username)
+	if id, taken := r.usernames[key]; taken && id != player.ID {
+		return ErrUsernameTaken
+	}
+
+	delete(r.usernames, UsernameKey(old.Username))
+	r.usernames[key] = player.ID
+	r.players[player.ID] = player
+	return nil
+}
@@ -0,0 +1,234 @@
+package players
+
+import (
+	"errors"
+	"fmt"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/rating"
+	"net/mail"
+	"sync"
+)
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 20
+)
+
+var (
+	ErrInvalidUsername    = errors.New("invalid username")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// ValidateUsername accepts MinUsernameLength to MaxUsernameLength letters,
+// digits, underscores and hyphens.
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return fmt.Errorf("%w: must be %d to %d characters", ErrInvalidUsername, MinUsernameLength, MaxUsernameLength)
+	}
+	for _, c := range username {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '-':
+		default:
+			return fmt.Errorf("%w: only letters, digits, _ and - are allowed", ErrInvalidUsername)
+		}
+	}
+	return nil
+}
+
+// ValidateEmail accepts an empty email, since it is optional, or a bare
+// address such as alice@example.com.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+// ProfileUpdate holds the profile fields to change. Nil fields are left as
+// they are.
+type ProfileUpdate struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
+	Password *string `json:"password"`
+}
+
+type Option func(*Service)
+
+func WithUUIDGenerator(generator game.UUIDGenerator) Option {
+	return func(s *Service) {
+		s.ids = generator
+	}
+}
+
+func WithClock(clock game.Clock) Option {
+	return func(s *Service) {
+		s.clock = clock
+	}
+}
+
+// Service registers players and changes their profiles and ratings, checking
+// every change before it reaches the repository.
+type Service struct {
+	repo  Repository
+	ids   game.UUIDGenerator
+	clock game.Clock
+	// mu serialises read-modify-write updates so a profile change and a
+	// rating change cannot overwrite each other.
+	mu sync.Mutex
+}
+
+func NewService(repo Repository, opts ...Option) *Service {
+	s := &Service{
+		repo:  repo,
+		ids:   &game.DefaultUUIDGenerator{},
+		clock: game.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register creates a player with the default rating.
+func (s *Service) Register(username, email, password string) (models.Player, error) {
+	if err := ValidateUsername(username); err != nil {
+		return models.Player{}, err
+	}
+	if err := ValidateEmail(email); err != nil {
+		return models.Player{}, err
+	}
+	if err := ValidatePassword(password); err != nil {
+		return models.Player{}, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return models.Player{}, err
+	}
+
+	player := models.Player{
+		ID:           s.ids.Generate(),
+		Username:     username,
+		Email:        email,
+		CreatedAt:    s.clock.Now().Unix(),
+		Rating:       rating.Default(),
+		PasswordHash: hash,
+	}
+	if err := s.repo.Create(player); err != nil {
+		return models.Player{}, err
+	}
+	return player, nil
+}
+
+// Authenticate returns the player with the given username and password.
+// A wrong password and an unknown username both give ErrInvalidCredentials.
+func (s *Service) Authenticate(username, password string) (models.Player, error) {
+	player, err := s.repo.GetByUsername(username)
+	if errors.Is(err, ErrPlayerNotFound) {
+		return models.Player{}, ErrInvalidCredentials
+	} else if err != nil {
+		return models.Player{}, err
+	}
+
+	if !CheckPassword(player.PasswordHash, password) {
+		return models.Player{}, ErrInvalidCredentials
+	}
+	return player, nil
+}
+
+func (s *Service) Get(id string) (models.Player, error) {
+	return s.repo.Get(id)
+}
+
+func (s *Service) GetByUsername(username string) (models.Player, error) {
+	return s.repo.GetByUsername(username)
+}
+
+func (s *Service) UpdateProfile(id string, update ProfileUpdate) (models.Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, err := s.repo.Get(id)
+	if err != nil {
+		return models.Player{}, err
+	}
+
+	if update.Username != nil {
+		if err := ValidateUsername(*update.Username); err != nil {
+			return models.Player{}, err
+		}
+		player.Username = *update.Username
+	}
+	if update.Email != nil {
+		if err := ValidateEmail(*update.Email); err != nil {
+			return models.Player{}, err
+		}
+		player.Email = *update.Email
+	}
+	if update.Password != nil {
+		if err := ValidatePassword(*update.Password); err != nil {
+			return models.Player{}, err
+		}
+		hash, err := HashPassword(*update.Password)
+		if err != nil {
+			return models.Player{}, err
+		}
+		player.PasswordHash = hash
+	}
+
+	if err := s.repo.Update(player); err != nil {
+		return models.Player{}, err
+	}
+	return player, nil
+}
+
+// Rating returns the player's rating, or the default rating for players who
+// are not registered.
+func (s *Service) Rating(id string) rating.Rating {
+	player, err := s.repo.Get(id)
+	if err != nil {
+		return rating.Default()
+	}
+	return ratingOf(player)
+}
+
+func ratingOf(player models.Player) rating.Rating {
+	if player.Rating.IsZero() {
+		return rating.Default()
+	}
+	return player.Rating
+}
+
+// RecordGame updates both players' ratings after a game, treating it as its
+// own rating period. score is p1's result: rating.Win, rating.Draw or
+// rating.Loss.
+func (s *Service) RecordGame(p1, p2 string, score float64) (rating.Change, rating.Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player1, err := s.repo.Get(p1)
+	if err != nil {
+		return rating.Change{}, rating.Change{}, err
+	}
+	player2, err := s.repo.Get(p2)
+	if err != nil {
+		return rating.Change{}, rating.Change{}, err
+	}
+
+	c1, c2 := rating.RateGame(ratingOf(player1), ratingOf(player2), score)
+	player1.Rating, player2.Rating = c1.After, c2.After
+
+	if err := s.repo.Update(player1); err != nil {
+		return rating.Change{}, rating.Change{}, err
+	}
+	if err := s.repo.Update(player2); err != nil {
+		return rating.Change{}, rating.Change{}, err
+	}
+	return c1, c2, nil
+}
@@ -0,0 +1,197 @@
+package players
+
+import (
+	"errors"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/rating"
+	"testing"
+	"time"
+)
+
+func init() {
+	passwordIterations = 1000
+}
+
+func newTestService() *Service {
+	return NewService(NewMemoryRepository(),
+		WithUUIDGenerator(game.NewMockUUIDGenerator([]string{"player1", "player2", "player3"})),
+		WithClock(game.NewMockClock(time.Unix(1000, 0))),
+	)
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		valid    bool
+	}{
+		{"alice", true},
+		{"Bob_99", true},
+		{"x-y", true},
+		{"ab", false},
+		{"abcdefghijklmnopqrstu", false},
+		{"with space", false},
+		{"émile", false},
+	}
+	for _, tt := range tests {
+		err := ValidateUsername(tt.username)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateUsername(%q) = %v, expected valid %v", tt.username, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("Expected ErrInvalidUsername for %q, got %v", tt.username, err)
+		}
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	for _, email := range []string{"", "alice@example.com"} {
+		if err := ValidateEmail(email); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", email, err)
+		}
+	}
+	for _, email := range []string{"alice", "Alice <alice@example.com>", "@example.com"} {
+		if err := ValidateEmail(email); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("Expected %q to be invalid, got %v", email, err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("Creates a player", func(t *testing.T) {
+		s := newTestService()
+		player, err := s.Register("alice", "alice@example.com", "password1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if player.ID != "player1" || player.CreatedAt != 1000 || player.Rating != rating.Default() {
+			t.Errorf("Unexpected player %+v", player)
+		}
+
+		stored, err := s.Get("player1")
+		if err != nil || stored != player {
+			t.Errorf("Expected the player to be stored, got %+v, %v", stored, err)
+		}
+	})
+
+	t.Run("Usernames are unique regardless of case", func(t *testing.T) {
+		s := newTestService()
+		s.Register("alice", "", "password1")
+		if _, err := s.Register("ALICE", "", "password1"); !errors.Is(err, ErrUsernameTaken) {
+			t.Errorf("Expected ErrUsernameTaken, got %v", err)
+		}
+
+		player, err := s.GetByUsername("Alice")
+		if err != nil || player.ID != "player1" {
+			t.Errorf("Expected to find alice by username, got %+v, %v", player, err)
+		}
+	})
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		s := newTestService()
+		if _, err := s.Register("a", "", "password1"); !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("Expected ErrInvalidUsername, got %v", err)
+		}
+		if _, err := s.Register("alice", "not-an-email", "password1"); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("Expected ErrInvalidEmail, got %v", err)
+		}
+		if _, err := s.Register("alice", "", "short"); !errors.Is(err, ErrInvalidPassword) {
+			t.Errorf("Expected ErrInvalidPassword, got %v", err)
+		}
+	})
+}
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := HashPassword("password1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !CheckPassword(hash, "password1") {
+		t.Error("Expected the password to match its hash")
+	}
+	if CheckPassword(hash, "password2") {
+		t.Error("Expected a different password not to match")
+	}
+	if CheckPassword("", "password1") || CheckPassword("plain$1$x$y", "password1") {
+		t.Error("Expected malformed hashes not to match")
+	}
+
+	again, _ := HashPassword("password1")
+	if again == hash {
+		t.Error("Expected hashes to be salted")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestService()
+	s.Register("alice", "", "password1")
+
+	player, err := s.Authenticate("Alice", "password1")
+	if err != nil || player.ID != "player1" {
+		t.Fatalf("Expected alice to log in, got %+v, %v", player, err)
+	}
+	if _, err := s.Authenticate("alice", "password2"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := s.Authenticate("bob", "password1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown player, got %v", err)
+	}
+
+	password := "password2"
+	s.UpdateProfile("player1", ProfileUpdate{Password: &password})
+	if _, err := s.Authenticate("alice", "password2"); err != nil {
+		t.Errorf("Expected the new password to work, got %v", err)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	s := newTestService()
+	s.Register("alice", "alice@example.com", "password1")
+	s.Register("bob", "", "password1")
+
+	name := "alicia"
+	player, err := s.UpdateProfile("player1", ProfileUpdate{Username: &name})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if player.Username != "alicia" || player.Email != "alice@example.com" {
+		t.Errorf("Expected only the username to change, got %+v", player)
+	}
+	if _, err := s.GetByUsername("alice"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected the old username to be free, got %v", err)
+	}
+	if _, err := s.Register("alice", "", "password1"); err != nil {
+		t.Errorf("Expected the old username to be reusable, got %v", err)
+	}
+
+	taken := "bob"
+	if _, err := s.UpdateProfile("player1", ProfileUpdate{Username: &taken}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("Expected ErrUsernameTaken, got %v", err)
+	}
+	if _, err := s.UpdateProfile("missing", ProfileUpdate{}); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
+
+func TestRecordGame(t *testing.T) {
+	s := newTestService()
+	s.Register("alice", "", "password1")
+	s.Register("bob", "", "password1")
+
+	c1, c2, err := s.RecordGame("player1", "player2", rating.Win)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c1.Delta <= 0 || c2.Delta >= 0 {
+		t.Errorf("Expected the winner to gain and the loser to lose, got %+v and %+v", c1, c2)
+	}
+	if s.Rating("player1") != c1.After || s.Rating("player2") != c2.After {
+		t.Error("Expected the new ratings to be stored")
+	}
+	if s.Rating("missing") != rating.Default() {
+		t.Error("Expected unknown players to have the default rating")
+	}
+
+	if _, _, err := s.RecordGame("player1", "missing", rating.Win); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package rating
+
+// Change describes how a single game moved a player's rating.
+type Change struct {
+	Before Rating  `json:"before"`
+	After  Rating  `json:"after"`
+	Delta  float64 `json:"delta"`
+}
+
+// RateGame treats a game between two players as its own rating period and
+// returns how it moves each of them. score is p1's result: Win, Draw or Loss.
+func RateGame(r1, r2 Rating, score float64) (Change, Change) {
+	new1 := Update(r1, []Result{{Opponent: r2, Score: score}})
+	new2 := Update(r2, []Result{{Opponent: r1, Score: 1 - score}})
+	return newChange(r1, new1), newChange(r2, new2)
+}
+
+func newChange(before, after Rating) Change {
+	return Change{
+		Before: before,
+		After:  after,
+		Delta:  after.Rating - before.Rating,
+	}
+}
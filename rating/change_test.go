@@ -0,0 +1,33 @@
+package rating
+
+import "testing"
+
+func TestRateGame(t *testing.T) {
+	t.Run("Winner gains what the loser loses", func(t *testing.T) {
+		c1, c2 := RateGame(Default(), Default(), Win)
+
+		if c1.Delta <= 0 {
+			t.Fatalf("Expected winner to gain rating, got %.2f", c1.Delta)
+		}
+		if c2.Delta >= 0 {
+			t.Fatalf("Expected loser to lose rating, got %.2f", c2.Delta)
+		}
+		if !approx(c1.Delta, -c2.Delta, 0.001) {
+			t.Fatalf("Expected symmetric changes between equal players, got %.2f and %.2f", c1.Delta, c2.Delta)
+		}
+		if c1.Before != Default() || c1.After.Rating != c1.Before.Rating+c1.Delta {
+			t.Fatalf("Expected the change to go from the old rating to the new one, got %+v", c1)
+		}
+	})
+
+	t.Run("Draw between equal players keeps ratings", func(t *testing.T) {
+		c1, c2 := RateGame(Default(), Default(), Draw)
+
+		if !approx(c1.Delta, 0, 0.001) || !approx(c2.Delta, 0, 0.001) {
+			t.Fatalf("Expected no rating change, got %.2f and %.2f", c1.Delta, c2.Delta)
+		}
+		if c1.After.Deviation >= c1.Before.Deviation {
+			t.Fatal("Expected deviation to shrink after a game")
+		}
+	})
+}
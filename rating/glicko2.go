@@ -0,0 +1,134 @@
+// Package rating implements the Glicko-2 rating system.
+package rating
+
+import "math"
+
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+
+	// tau constrains how quickly volatility can change.
+	tau = 0.5
+	// scale converts between the Glicko and Glicko-2 scales.
+	scale = 173.7178
+	// convergence is the tolerance of the volatility iteration.
+	convergence = 0.000001
+)
+
+const (
+	Loss = 0.0
+	Draw = 0.5
+	Win  = 1.0
+)
+
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+func Default() Rating {
+	return Rating{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// IsZero reports whether the rating was never set.
+func (r Rating) IsZero() bool {
+	return r == Rating{}
+}
+
+// Result is one game from a player's point of view. Score is Win, Draw or
+// Loss.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Update returns the player's rating after a rating period containing the
+// given results. With no results only the deviation grows.
+func Update(player Rating, results []Result) Rating {
+	mu := (player.Rating - DefaultRating) / scale
+	phi := player.Deviation / scale
+	sigma := player.Volatility
+
+	if len(results) == 0 {
+		return Rating{
+			Rating:     player.Rating,
+			Deviation:  math.Min(math.Sqrt(phi*phi+sigma*sigma)*scale, DefaultDeviation),
+			Volatility: sigma,
+		}
+	}
+
+	var vInv, improvement float64
+	for _, result := range results {
+		muJ := (result.Opponent.Rating - DefaultRating) / scale
+		gJ := g(result.Opponent.Deviation / scale)
+		e := expected(mu, muJ, gJ)
+
+		vInv += gJ * gJ * e * (1 - e)
+		improvement += gJ * (result.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * improvement
+
+	sigma = newVolatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * improvement
+
+	return Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  math.Min(phi*scale, DefaultDeviation),
+		Volatility: sigma,
+	}
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// newVolatility solves for the new volatility with the Illinois algorithm,
+// as in step 5 of Glickman's paper.
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > convergence {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,51 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func approx(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("Example from Glickman's paper", func(t *testing.T) {
+		player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+		results := []Result{
+			{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: Win},
+			{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: Loss},
+			{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: Loss},
+		}
+
+		updated := Update(player, results)
+		if !approx(updated.Rating, 1464.06, 0.01) {
+			t.Fatalf("Expected rating 1464.06, got %.2f", updated.Rating)
+		}
+		if !approx(updated.Deviation, 151.52, 0.01) {
+			t.Fatalf("Expected deviation 151.52, got %.2f", updated.Deviation)
+		}
+		if !approx(updated.Volatility, 0.05999, 0.00001) {
+			t.Fatalf("Expected volatility 0.05999, got %.5f", updated.Volatility)
+		}
+	})
+
+	t.Run("No games grows the deviation", func(t *testing.T) {
+		player := Rating{Rating: 1500, Deviation: 50, Volatility: 0.06}
+
+		updated := Update(player, nil)
+		if updated.Rating != player.Rating {
+			t.Fatalf("Expected rating to stay %.2f, got %.2f", player.Rating, updated.Rating)
+		}
+		if updated.Deviation <= player.Deviation {
+			t.Fatalf("Expected deviation to grow from %.2f, got %.2f", player.Deviation, updated.Deviation)
+		}
+	})
+
+	t.Run("Deviation never exceeds the default", func(t *testing.T) {
+		updated := Update(Default(), nil)
+		if updated.Deviation > DefaultDeviation {
+			t.Fatalf("Expected deviation at most %.0f, got %.2f", DefaultDeviation, updated.Deviation)
+		}
+	})
+}
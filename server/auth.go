@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/auth"
+	"ldriko/rps-backend/players"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// bearerToken returns the access token of a request, from the Authorization
+// header or, since browsers cannot set headers on a websocket upgrade, from
+// the "token" query parameter.
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			return ""
+		}
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authenticate verifies the request's access token and returns its claims.
+func (s *Server) authenticate(r *http.Request) (auth.Claims, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return auth.Claims{}, auth.ErrInvalidToken
+	}
+	return s.tokens.Verify(token, auth.KindAccess)
+}
+
+func writeAuthError(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer`)
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
+
+type sessionResponse struct {
+	auth.TokenPair
+	Player profile `json:"player"`
+}
+
+// HandleLogin serves POST /login, trading a JSON body with "username" and
+// "password" for a token pair.
+func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	player, err := s.players.Authenticate(body.Username, body.Password)
+	if errors.Is(err, players.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+
+	tokens, err := s.tokens.Issue(player.ID)
+	if err != nil {
+		log.Printf("failed to issue tokens for %s: %v", player.ID, err)
+		http.Error(w, "failed to issue tokens", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessionResponse{TokenPair: tokens, Player: publicProfile(player)})
+}
+
+// HandleRefresh serves POST /token/refresh, trading a JSON body with
+// "refreshToken" for a new token pair.
+func (s *Server) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := s.tokens.Verify(body.RefreshToken, auth.KindRefresh)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	// Tokens of a player who no longer exists are not renewed.
+	player, err := s.players.Get(claims.Subject)
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+
+	tokens, err := s.tokens.Issue(player.ID)
+	if err != nil {
+		log.Printf("failed to issue tokens for %s: %v", player.ID, err)
+		http.Error(w, "failed to issue tokens", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessionResponse{TokenPair: tokens, Player: publicProfile(player)})
+}
+
+// handleAuthenticate renews the session of a connection with a fresh access
+// token, so it is not closed when the token it connected with expires.
+func (s *Server) handleAuthenticate(conn *Connection, data map[string]any) {
+	token, _ := data["token"].(string)
+	claims, err := s.tokens.Verify(token, auth.KindAccess)
+	if err == nil && claims.Subject != conn.playerID {
+		err = auth.ErrInvalidToken
+	}
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	s.scheduleSessionExpiry(conn, claims.Expiry())
+	conn.SendMessage(Message{
+		Type: "authenticated",
+		Data: map[string]any{"expiresAt": claims.Expiry()},
+	})
+}
+
+// scheduleSessionExpiry closes the connection once its access token expires,
+// replacing any earlier deadline.
+func (s *Server) scheduleSessionExpiry(conn *Connection, expiresAt time.Time) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if conn.expiry != nil {
+		conn.expiry.Stop()
+	}
+	conn.expiry = s.clock.AfterFunc(expiresAt.Sub(s.clock.Now()), func() {
+		log.Printf("session of player %s expired", conn.playerID)
+		conn.ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session expired"),
+			time.Now().Add(time.Second))
+		conn.ws.Close()
+	})
+}
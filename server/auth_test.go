@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"ldriko/rps-backend/auth"
+	"ldriko/rps-backend/game"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newAuthClock() *game.MockClock {
+	return game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func TestWebSocketAuthentication(t *testing.T) {
+	clock := newAuthClock()
+	s, url, token := newTestServer(t, DefaultConfig(), game.WithClock(clock))
+
+	forger, _ := auth.NewIssuer(auth.RandomSecret(), auth.WithClock(clock))
+	forged, _ := forger.Issue("player1")
+	pair, _ := s.tokens.Issue("player1")
+	stranger, _ := s.tokens.Issue("nobody")
+
+	dial := func(query string, header http.Header) (*websocket.Conn, *http.Response, error) {
+		return websocket.DefaultDialer.Dial(url+query, header)
+	}
+
+	t.Run("Rejects bad tokens", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			query  string
+			header string
+		}{
+			{"missing", "", ""},
+			{"forged", "?token=" + forged.AccessToken, ""},
+			{"refresh", "?token=" + pair.RefreshToken, ""},
+			{"unknown player", "?token=" + stranger.AccessToken, ""},
+			{"malformed header", "", "Token " + token},
+		}
+		for _, tt := range tests {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Authorization", tt.header)
+			}
+			ws, resp, err := dial(tt.query, header)
+			if err == nil {
+				ws.Close()
+				t.Errorf("%s token: expected the upgrade to be refused", tt.name)
+				continue
+			}
+			if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s token: expected 401 Unauthorized, got %v", tt.name, resp)
+			}
+		}
+	})
+
+	t.Run("Connects as the token's subject", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+token)
+		ws, _, err := dial("", header)
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		defer ws.Close()
+
+		waitFor(t, func() bool { return isConnected(s, "player1") })
+	})
+
+	t.Run("Rejects expired tokens", func(t *testing.T) {
+		clock.Advance(auth.DefaultAccessTTL + time.Second)
+		ws, resp, err := dial("?token="+token, http.Header{})
+		if err == nil {
+			ws.Close()
+			t.Fatal("Expected the upgrade to be refused")
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 Unauthorized, got %v", resp)
+		}
+	})
+}
+
+func TestLogin(t *testing.T) {
+	s, _, _ := newTestServer(t, DefaultConfig())
+	bob, err := s.players.Register("bob", "", "password1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	login := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.HandleLogin(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+		return w
+	}
+
+	w := login(`{"username":"bob","password":"password1"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body)
+	}
+	var session sessionResponse
+	json.NewDecoder(w.Body).Decode(&session)
+	if session.Player.ID != bob.ID {
+		t.Errorf("Expected bob's profile, got %+v", session.Player)
+	}
+	claims, err := s.tokens.Verify(session.AccessToken, auth.KindAccess)
+	if err != nil || claims.Subject != bob.ID {
+		t.Errorf("Expected an access token for bob, got %+v, %v", claims, err)
+	}
+
+	if w := login(`{"username":"bob","password":"password2"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong password, got %d", w.Code)
+	}
+	if w := login(`not json`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad body, got %d", w.Code)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, _, _ := newTestServer(t, DefaultConfig())
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"refreshToken": token})
+		w := httptest.NewRecorder()
+		s.HandleRefresh(w, httptest.NewRequest(http.MethodPost, "/token/refresh", strings.NewReader(string(body))))
+		return w
+	}
+
+	pair, _ := s.tokens.Issue("player1")
+	w := refresh(pair.RefreshToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body)
+	}
+	var session sessionResponse
+	json.NewDecoder(w.Body).Decode(&session)
+	if claims, err := s.tokens.Verify(session.AccessToken, auth.KindAccess); err != nil || claims.Subject != "player1" {
+		t.Errorf("Expected a new access token for player1, got %+v, %v", claims, err)
+	}
+
+	if w := refresh(pair.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an access token, got %d", w.Code)
+	}
+	stranger, _ := s.tokens.Issue("nobody")
+	if w := refresh(stranger.RefreshToken); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown player, got %d", w.Code)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	clock := newAuthClock()
+	s, url, token := newTestServer(t, DefaultConfig(), game.WithClock(clock))
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+	if err != nil {
+		t.Fatalf("Expected the upgrade to succeed, got %v", err)
+	}
+	defer ws.Close()
+	waitFor(t, func() bool { return isConnected(s, "player1") })
+
+	t.Run("Tokens of another player are refused", func(t *testing.T) {
+		other, _ := s.tokens.Issue("player2")
+		ws.WriteJSON(Message{Type: "authenticate", Data: map[string]any{"token": other.AccessToken}})
+		if msg := readUntil(t, ws, "error"); msg.Data["message"] != auth.ErrInvalidToken.Error() {
+			t.Errorf("Expected an invalid token error, got %+v", msg)
+		}
+	})
+
+	t.Run("Authenticating again extends the session", func(t *testing.T) {
+		clock.Advance(auth.DefaultAccessTTL / 2)
+		fresh, _ := s.tokens.Issue("player1")
+		ws.WriteJSON(Message{Type: "authenticate", Data: map[string]any{"token": fresh.AccessToken}})
+		readUntil(t, ws, "authenticated")
+
+		// The first token has expired, yet the connection still answers.
+		clock.Advance(auth.DefaultAccessTTL / 2)
+		ws.WriteJSON(Message{Type: "start_round"})
+		readUntil(t, ws, "error")
+	})
+
+	t.Run("Expired sessions are closed", func(t *testing.T) {
+		clock.Advance(auth.DefaultAccessTTL / 2)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+					t.Errorf("Expected a policy violation close, got %v", err)
+				}
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config is the policy applied to websocket connections: who may upgrade,
+// what they may send and how dead connections are detected.
+type Config struct {
+	// AllowedOrigins lists the origins browsers may connect from, such as
+	// "https://example.com". A "*." prefix on the host, as in
+	// "https://*.example.com", allows any subdomain but not the domain
+	// itself, and a lone "*" allows every origin. Entries without a scheme
+	// match on the host alone, and a non-default port is part of the host.
+	// When empty, only same-origin requests are allowed. Requests without an
+	// Origin header do not come from a browser and are always allowed.
+	AllowedOrigins []string
+	// MaxMessageSize is the largest message in bytes a client may send; the
+	// connection is closed when it is exceeded. Zero means no limit.
+	MaxMessageSize    int64
+	ReadBufferSize    int
+	WriteBufferSize   int
+	EnableCompression bool
+	// Subprotocols are the protocols the server can speak, in order of
+	// preference. The first one the client also offers is selected.
+	Subprotocols []string
+
+	// PingInterval is how often the server pings each client. It must be
+	// shorter than PongTimeout.
+	PingInterval time.Duration
+	// PongTimeout is how long a connection may stay silent, sending neither
+	// messages nor pongs, before it is considered dead and dropped.
+	PongTimeout time.Duration
+	// WriteTimeout bounds every write to a client.
+	WriteTimeout time.Duration
+
+	// ResumeWindow is how long a dropped player's session is kept for them
+	// to resume it.
+	ResumeWindow time.Duration
+	// ResumeBufferSize is how many of the latest messages a session keeps
+	// to replay. Clients that missed more get a state_sync snapshot.
+	ResumeBufferSize int
+	// DisconnectGracePeriod is how long a player who left a game has to come
+	// back before forfeiting it. Zero disables the forfeit.
+	DisconnectGracePeriod time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxMessageSize:  4096,
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		PingInterval:    54 * time.Second,
+		PongTimeout:     60 * time.Second,
+		WriteTimeout:    10 * time.Second,
+
+		ResumeWindow:     2 * time.Minute,
+		ResumeBufferSize: 128,
+
+		DisconnectGracePeriod: 30 * time.Second,
+	}
+}
+
+func (c Config) upgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:    c.ReadBufferSize,
+		WriteBufferSize:   c.WriteBufferSize,
+		EnableCompression: c.EnableCompression,
+		Subprotocols:      c.Subprotocols,
+		CheckOrigin:       c.checkOrigin,
+	}
+}
+
+func (c Config) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if len(c.AllowedOrigins) == 0 {
+		return strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if originAllowed(allowed, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// originAllowed reports whether the allowlist entry matches the origin.
+func originAllowed(allowed string, origin *url.URL) bool {
+	if allowed == "*" {
+		return true
+	}
+
+	host := allowed
+	if scheme, rest, found := strings.Cut(allowed, "://"); found {
+		if !strings.EqualFold(scheme, origin.Scheme) {
+			return false
+		}
+		host = rest
+	}
+
+	if suffix, found := strings.CutPrefix(host, "*."); found {
+		// The dot keeps "*.example.com" from matching "badexample.com".
+		return strings.HasSuffix(strings.ToLower(origin.Host), "."+strings.ToLower(suffix))
+	}
+	return strings.EqualFold(host, origin.Host)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"ldriko/rps-backend/auth"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/players"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	config := Config{AllowedOrigins: []string{
+		"https://example.com",
+		"https://*.example.org",
+		"localhost:3000",
+	}}
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"https://example.com", true},
+		{"https://EXAMPLE.com", true},
+		{"http://example.com", false},
+		{"https://www.example.com", false},
+		{"https://app.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://badexample.org", false},
+		{"http://app.example.org", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"https://evil.com", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://rps.example.net/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := config.checkOrigin(r); got != tt.allowed {
+			t.Errorf("Origin %q: expected allowed %v, got %v", tt.origin, tt.allowed, got)
+		}
+	}
+
+	t.Run("Same origin by default", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://rps.example.net/ws", nil)
+		r.Header.Set("Origin", "https://rps.example.net")
+		if !DefaultConfig().checkOrigin(r) {
+			t.Error("Expected a same-origin request to be allowed")
+		}
+		r.Header.Set("Origin", "https://evil.com")
+		if DefaultConfig().checkOrigin(r) {
+			t.Error("Expected a cross-origin request to be refused")
+		}
+	})
+
+	t.Run("Wildcard allows everything", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://rps.example.net/ws", nil)
+		r.Header.Set("Origin", "https://evil.com")
+		if !(Config{AllowedOrigins: []string{"*"}}).checkOrigin(r) {
+			t.Error("Expected every origin to be allowed")
+		}
+	})
+}
+
+// newTestServer serves the websocket endpoint of a server with config and
+// returns the server, its URL and an access token for a registered player.
+func newTestServer(t *testing.T, config Config, opts ...game.ManagerOption) (*Server, string, string) {
+	t.Helper()
+
+	repo := players.NewMemoryRepository()
+	repo.Create(models.Player{ID: "player1", Username: "alice"})
+	gm := game.NewManager(opts...)
+	tokens, _ := auth.NewIssuer(auth.RandomSecret(), auth.WithClock(gm.Clock()))
+	s := NewServerWithManager(gm,
+		WithConfig(config),
+		WithPlayers(players.NewService(repo)),
+		WithTokens(tokens),
+	)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(s.HandleWebSocket))
+	t.Cleanup(httpServer.Close)
+
+	pair, err := tokens.Issue("player1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return s, "ws" + strings.TrimPrefix(httpServer.URL, "http"), pair.AccessToken
+}
+
+func TestUpgradePolicy(t *testing.T) {
+	config := DefaultConfig()
+	config.AllowedOrigins = []string{"https://*.example.com"}
+	config.Subprotocols = []string{"rps.v2", "rps.v1"}
+	_, url, token := newTestServer(t, config)
+
+	dial := func(origin string, subprotocols ...string) (*websocket.Conn, *http.Response, error) {
+		dialer := websocket.Dialer{Subprotocols: subprotocols}
+		header := http.Header{}
+		header.Set("Origin", origin)
+		return dialer.Dial(url+"?token="+token, header)
+	}
+
+	t.Run("Cross-origin upgrades are refused", func(t *testing.T) {
+		ws, resp, err := dial("https://evil.com")
+		if err == nil {
+			ws.Close()
+			t.Fatal("Expected the upgrade to be refused")
+		}
+		if resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 Forbidden, got %v", resp)
+		}
+	})
+
+	t.Run("Allowed origins connect", func(t *testing.T) {
+		ws, _, err := dial("https://app.example.com", "rps.v1", "rps.v2")
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		defer ws.Close()
+
+		if ws.Subprotocol() != "rps.v2" {
+			t.Errorf("Expected the server's preferred subprotocol rps.v2, got %q", ws.Subprotocol())
+		}
+	})
+
+	t.Run("Oversized messages close the connection", func(t *testing.T) {
+		ws, _, err := dial("https://app.example.com")
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		defer ws.Close()
+
+		big := strings.Repeat("x", int(config.MaxMessageSize)+1)
+		ws.WriteJSON(Message{Type: "make_move", Data: map[string]any{"move": big}})
+
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+					t.Errorf("Expected a message too big close, got %v", err)
+				}
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"ldriko/rps-backend/game"
+	"log"
+	"time"
+)
+
+// graceKey identifies the grace period of a player who left a game.
+type graceKey struct {
+	gameID   string
+	playerID string
+}
+
+// startGracePeriod tells the opponent of a player who left the game at since
+// how long they have to come back, and forfeits the game for them when they
+// do not.
+func (s *Server) startGracePeriod(gm *game.Game, playerID string, since time.Time) {
+	grace := s.config.DisconnectGracePeriod
+	opponent := opponentOf(gm, playerID)
+	if grace <= 0 || opponent == "" || gm.IsOver() {
+		return
+	}
+
+	key := graceKey{gameID: gm.ID, playerID: playerID}
+	s.mu.Lock()
+	if timer, exists := s.graceTimers[key]; exists {
+		timer.Stop()
+	}
+	s.graceTimers[key] = s.clock.AfterFunc(since.Add(grace).Sub(s.clock.Now()), func() {
+		s.handleGraceExpired(key)
+	})
+	s.mu.Unlock()
+
+	s.sendInGame(gm.ID, opponent, Message{
+		Type: "opponent_disconnected",
+		Data: map[string]any{
+			"playerID": playerID,
+			"deadline": since.Add(grace),
+		},
+	})
+}
+
+// endGracePeriod stops the grace period of a player who came back, telling
+// their opponent. It does nothing for players who were not gone.
+func (s *Server) endGracePeriod(gm *game.Game, playerID string) {
+	key := graceKey{gameID: gm.ID, playerID: playerID}
+	s.mu.Lock()
+	timer, exists := s.graceTimers[key]
+	if exists {
+		timer.Stop()
+		delete(s.graceTimers, key)
+	}
+	s.mu.Unlock()
+
+	if exists {
+		s.sendInGame(gm.ID, opponentOf(gm, playerID), Message{
+			Type: "opponent_reconnected",
+			Data: map[string]any{"playerID": playerID},
+		})
+	}
+}
+
+// clearGracePeriods stops the grace periods of a game that is over.
+func (s *Server) clearGracePeriods(gameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, timer := range s.graceTimers {
+		if key.gameID == gameID {
+			timer.Stop()
+			delete(s.graceTimers, key)
+		}
+	}
+}
+
+func (s *Server) handleGraceExpired(key graceKey) {
+	gm, exists := s.gm.GetGame(key.gameID)
+	if !exists {
+		return
+	}
+
+	abandoned, err := gm.Abandon(key.playerID)
+	if err != nil {
+		log.Printf("failed to forfeit game %s for player %s: %v", key.gameID, key.playerID, err)
+		return
+	}
+	if abandoned {
+		log.Printf("player %s abandoned game %s", key.playerID, key.gameID)
+	}
+}
+
+// opponentOf returns the other seated player of the game, if there is one.
+func opponentOf(gm *game.Game, playerID string) string {
+	view := gm.SpectatorView()
+	switch {
+	case playerID == "":
+		return ""
+	case playerID == view.P1:
+		return view.P2
+	case playerID == view.P2:
+		return view.P1
+	}
+	return ""
+}
+
+// sendInGame sends msg to the player's connections in the game.
+func (s *Server) sendInGame(gameID, playerID string, msg Message) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, conn := range s.gameConns[gameID] {
+		if conn.playerID == playerID {
+			conn.SendMessage(msg)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/rating"
+	"log"
+)
+
+// handleGameEvent translates domain events from the game manager into
+// websocket messages for everyone connected to the game.
+func (s *Server) handleGameEvent(event game.Event) {
+	gm, exists := s.gm.GetGame(event.GameID())
+	if !exists {
+		return
+	}
+
+	switch e := event.(type) {
+	case game.GameReady:
+		s.broadcastGameView(gm, "game_ready", func(view game.GameView) map[string]any {
+			return map[string]any{
+				"gameID": gm.ID,
+				"p1":     e.P1,
+				"p2":     e.P2,
+				"game":   view,
+			}
+		})
+
+	case game.PlayerConnected:
+		s.broadcastToGame(gm.ID, Message{
+			Type: "player_joined",
+			Data: map[string]any{"playerID": e.PlayerID},
+		}, e.PlayerID)
+		s.endGracePeriod(gm, e.PlayerID)
+
+	case game.PlayerDisconnected:
+		s.broadcastToGame(gm.ID, Message{
+			Type: "player_left",
+			Data: map[string]any{"playerID": e.PlayerID},
+		}, e.PlayerID)
+		s.startGracePeriod(gm, e.PlayerID, e.At)
+
+	case game.RoundStarted:
+		s.broadcastGameView(gm, "round_started", func(view game.GameView) map[string]any {
+			return map[string]any{
+				"round":    view.CurrentRound,
+				"deadline": e.Round.Deadline,
+				"game":     view,
+			}
+		})
+		s.scheduleRoundTimeout(gm.ID, e.Round.Number, e.Round.Deadline)
+
+	case game.MoveSubmitted:
+		s.broadcastToGame(gm.ID, Message{
+			Type: "move_submitted",
+			Data: map[string]any{"playerID": e.PlayerID},
+		}, "")
+
+	case game.MoveCommitted:
+		s.broadcastToGame(gm.ID, Message{
+			Type: "move_committed",
+			Data: map[string]any{"playerID": e.PlayerID},
+		}, "")
+
+	case game.RevealPhaseStarted:
+		s.broadcastGameView(gm, "reveal_phase", func(view game.GameView) map[string]any {
+			return map[string]any{
+				"round":    view.CurrentRound,
+				"deadline": e.Round.Deadline,
+			}
+		})
+		s.scheduleRoundTimeout(gm.ID, e.Round.Number, e.Round.Deadline)
+
+	case game.MoveRevealed:
+		s.broadcastToGame(gm.ID, Message{
+			Type: "move_revealed",
+			Data: map[string]any{"playerID": e.PlayerID, "valid": e.Valid},
+		}, "")
+
+	case game.RoundResolved:
+		s.broadcastGameView(gm, "round_played", func(view game.GameView) map[string]any {
+			return map[string]any{
+				"round": view.Rounds[e.Round.Number-1],
+				"game":  view,
+			}
+		})
+
+	case game.GameFinished:
+		s.clearGracePeriods(gm.ID)
+		result := gm.Result()
+		data := map[string]any{
+			"gameID": gm.ID,
+			"winner": e.Winner,
+			"reason": e.Reason,
+			"score": map[string]any{
+				"p1": e.P1Wins,
+				"p2": e.P2Wins,
+			},
+			"rounds": result.Rounds,
+		}
+		if change, ok := s.recordRating(gm, e); ok {
+			data["ratingChange"] = change
+		}
+		s.broadcastToGame(gm.ID, Message{
+			Type: "game_over",
+			Data: data,
+		}, "")
+	}
+}
+
+// recordRating updates both players' ratings after a ranked game and returns
+// the change keyed by seat. Aborted games are not rated.
+func (s *Server) recordRating(gm *game.Game, e game.GameFinished) (map[string]rating.Change, bool) {
+	view := gm.SpectatorView()
+	if !view.Config.Ranked || view.Config.VsBot || e.Reason == game.EndReasonAborted || view.P1 == "" || view.P2 == "" {
+		return nil, false
+	}
+
+	score := rating.Draw
+	switch e.Winner {
+	case view.P1:
+		score = rating.Win
+	case view.P2:
+		score = rating.Loss
+	}
+
+	p1, p2, err := s.players.RecordGame(view.P1, view.P2, score)
+	if err != nil {
+		log.Printf("failed to rate game %s: %v", gm.ID, err)
+		return nil, false
+	}
+	return map[string]rating.Change{"p1": p1, "p2": p2}, true
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"ldriko/rps-backend/lobby"
+	"log"
+	"time"
+)
+
+const (
+	DefaultLobbySweepInterval = time.Minute
+	DefaultLobbyMaxAge        = 30 * time.Minute
+)
+
+// StartLobbySweeper closes lobbies idle for longer than maxAge every
+// interval until StopLobbySweeper is called.
+func (s *Server) StartLobbySweeper(interval, maxAge time.Duration) {
+	s.sweeperStop = make(chan struct{})
+	stop := s.sweeperStop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.expireLobbies(maxAge)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) StopLobbySweeper() {
+	if s.sweeperStop != nil {
+		close(s.sweeperStop)
+		s.sweeperStop = nil
+	}
+}
+
+func (s *Server) expireLobbies(maxAge time.Duration) {
+	for _, l := range s.lobbies.CleanupExpiredLobbies(maxAge) {
+		log.Printf("lobby %s expired", l.Code)
+		s.sendToLobby(l, Message{
+			Type: "lobby_closed",
+			Data: map[string]any{"code": l.Code, "reason": "expired"},
+		}, "")
+	}
+}
+
+func (s *Server) handleCreateLobby(conn *Connection, data map[string]any) {
+	config, err := matchConfigFromData(data)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	l, err := s.lobbies.Create(conn.playerID, config)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	conn.SendMessage(Message{
+		Type: "lobby_created",
+		Data: map[string]any{"code": l.Code, "lobby": l},
+	})
+}
+
+func (s *Server) handleJoinLobby(conn *Connection, data map[string]any) {
+	code, ok := data["code"].(string)
+	if !ok {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": "invalid code"},
+		})
+		return
+	}
+
+	l, err := s.lobbies.Join(code, conn.playerID)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	s.sendToLobby(l, Message{
+		Type: "lobby_updated",
+		Data: map[string]any{"lobby": l},
+	}, "")
+}
+
+func (s *Server) handleLeaveLobby(conn *Connection, _ map[string]any) {
+	if err := s.leaveLobby(conn.playerID); err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	conn.SendMessage(Message{
+		Type: "lobby_left",
+		Data: map[string]any{},
+	})
+}
+
+// leaveLobby takes the player out of their lobby and tells whoever is left.
+func (s *Server) leaveLobby(playerID string) error {
+	l, closed, err := s.lobbies.Leave(playerID)
+	if err != nil {
+		return err
+	}
+
+	if closed {
+		s.sendToLobby(l, Message{
+			Type: "lobby_closed",
+			Data: map[string]any{"code": l.Code, "reason": "host_left"},
+		}, playerID)
+		return nil
+	}
+
+	s.sendToLobby(l, Message{
+		Type: "lobby_updated",
+		Data: map[string]any{"lobby": l},
+	}, "")
+	return nil
+}
+
+func (s *Server) handleLobbyKick(conn *Connection, data map[string]any) {
+	playerID, ok := data["player_id"].(string)
+	if !ok {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": "invalid player_id"},
+		})
+		return
+	}
+
+	l, err := s.lobbies.Kick(conn.playerID, playerID)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	s.sendToPlayer(playerID, Message{
+		Type: "lobby_kicked",
+		Data: map[string]any{"code": l.Code},
+	})
+	s.sendToLobby(l, Message{
+		Type: "lobby_updated",
+		Data: map[string]any{"lobby": l},
+	}, "")
+}
+
+// handleLobbySettings replaces the match config of the host's lobby using
+// the same fields as join_game.
+func (s *Server) handleLobbySettings(conn *Connection, data map[string]any) {
+	config, err := matchConfigFromData(data)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	l, err := s.lobbies.SetConfig(conn.playerID, config)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	s.sendToLobby(l, Message{
+		Type: "lobby_updated",
+		Data: map[string]any{"lobby": l},
+	}, "")
+}
+
+// handleStartLobby creates the game for the host's lobby. The guest is
+// checked before the lobby is closed, so a start that fails leaves the lobby
+// as it was.
+func (s *Server) handleStartLobby(conn *Connection, _ map[string]any) {
+	if l, exists := s.lobbies.LobbyOf(conn.playerID); exists && l.Host == conn.playerID && l.Guest != "" {
+		s.mu.RLock()
+		_, guestConnected := s.conns[l.Guest]
+		s.mu.RUnlock()
+
+		if !guestConnected {
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": "guest is not connected"},
+			})
+			return
+		}
+	}
+
+	l, err := s.lobbies.Start(conn.playerID)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	// The guest may still drop in between; their lobby is gone by now, so
+	// both are told it closed.
+	s.mu.RLock()
+	guest, guestConnected := s.conns[l.Guest]
+	s.mu.RUnlock()
+
+	if !guestConnected {
+		s.sendToLobby(l, Message{
+			Type: "lobby_closed",
+			Data: map[string]any{"code": l.Code, "reason": "guest_left"},
+		}, "")
+		return
+	}
+
+	if _, err := s.startGame(conn, guest, l.Config, "lobby_started"); err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+	}
+}
+
+// sendToLobby sends a message to the host and guest of a lobby.
+func (s *Server) sendToLobby(l lobby.Lobby, msg Message, excludePlayerID string) {
+	for _, playerID := range []string{l.Host, l.Guest} {
+		if playerID != "" && playerID != excludePlayerID {
+			s.sendToPlayer(playerID, msg)
+		}
+	}
+}
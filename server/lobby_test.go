@@ -0,0 +1,229 @@
+package server
+
+import (
+	"encoding/json"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/lobby"
+	"testing"
+	"time"
+)
+
+// bufferedConn registers a connection without a socket whose messages the
+// test reads straight from its send buffer.
+func bufferedConn(s *Server, playerID string) *Connection {
+	conn := &Connection{send: make(chan []byte, 256), playerID: playerID}
+	s.mu.Lock()
+	s.conns[playerID] = conn
+	s.mu.Unlock()
+	return conn
+}
+
+// nextOfType reads messages sent to conn until one of type msgType arrives.
+func nextOfType(t *testing.T, conn *Connection, msgType string) Message {
+	t.Helper()
+
+	for {
+		select {
+		case data := <-conn.send:
+			var msg Message
+			json.Unmarshal(data, &msg)
+			if msg.Type == msgType {
+				return msg
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a %s message", msgType)
+		}
+	}
+}
+
+// lobbyOf decodes the lobby carried by a lobby message.
+func lobbyOf(msg Message) lobby.Lobby {
+	var l lobby.Lobby
+	data, _ := json.Marshal(msg.Data["lobby"])
+	json.Unmarshal(data, &l)
+	return l
+}
+
+// createLobby has host create a lobby and guest join it, and returns its
+// code.
+func createLobby(t *testing.T, s *Server, host, guest *Connection) string {
+	t.Helper()
+
+	s.handleMessage(host, &Message{Type: "create_lobby", Data: map[string]any{}})
+	code := nextOfType(t, host, "lobby_created").Data["code"].(string)
+	if guest != nil {
+		s.handleMessage(guest, &Message{Type: "join_lobby", Data: map[string]any{"code": code}})
+		nextOfType(t, host, "lobby_updated")
+		nextOfType(t, guest, "lobby_updated")
+	}
+	return code
+}
+
+func TestLobbyMessages(t *testing.T) {
+	t.Run("Joining tells both players", func(t *testing.T) {
+		s := NewServer()
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+
+		s.handleMessage(host, &Message{Type: "create_lobby", Data: map[string]any{"format": "first_to_3"}})
+		created := nextOfType(t, host, "lobby_created")
+		code := created.Data["code"].(string)
+		if l := lobbyOf(created); l.Host != "player1" || l.Config.Rounds != 3 {
+			t.Errorf("Expected a first to 3 lobby hosted by player1, got %+v", l)
+		}
+
+		s.handleMessage(guest, &Message{Type: "join_lobby", Data: map[string]any{"code": code}})
+		for _, conn := range []*Connection{host, guest} {
+			if l := lobbyOf(nextOfType(t, conn, "lobby_updated")); l.Guest != "player2" {
+				t.Errorf("Expected player2 to be the guest, got %+v", l)
+			}
+		}
+
+		third := bufferedConn(s, "player3")
+		s.handleMessage(third, &Message{Type: "join_lobby", Data: map[string]any{"code": code}})
+		if msg := nextOfType(t, third, "error"); msg.Data["message"] != lobby.ErrLobbyFull.Error() {
+			t.Errorf("Expected the lobby to be full, got %+v", msg)
+		}
+		s.handleMessage(third, &Message{Type: "join_lobby", Data: map[string]any{}})
+		if msg := nextOfType(t, third, "error"); msg.Data["message"] != "invalid code" {
+			t.Errorf("Expected an invalid code error, got %+v", msg)
+		}
+	})
+
+	t.Run("Guests leave and hosts close", func(t *testing.T) {
+		s := NewServer()
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+		code := createLobby(t, s, host, guest)
+
+		s.handleMessage(guest, &Message{Type: "leave_lobby"})
+		nextOfType(t, guest, "lobby_left")
+		if l := lobbyOf(nextOfType(t, host, "lobby_updated")); l.Guest != "" {
+			t.Errorf("Expected the guest to be gone, got %+v", l)
+		}
+
+		s.handleMessage(guest, &Message{Type: "join_lobby", Data: map[string]any{"code": code}})
+		nextOfType(t, guest, "lobby_updated")
+		s.handleMessage(host, &Message{Type: "leave_lobby"})
+		nextOfType(t, host, "lobby_left")
+		if msg := nextOfType(t, guest, "lobby_closed"); msg.Data["code"] != code || msg.Data["reason"] != "host_left" {
+			t.Errorf("Expected the lobby to close as the host left, got %+v", msg)
+		}
+		if _, exists := s.lobbies.Get(code); exists {
+			t.Error("Expected the lobby to be removed")
+		}
+
+		s.handleMessage(guest, &Message{Type: "leave_lobby"})
+		if msg := nextOfType(t, guest, "error"); msg.Data["message"] != lobby.ErrNotInLobby.Error() {
+			t.Errorf("Expected a not in lobby error, got %+v", msg)
+		}
+	})
+
+	t.Run("Hosts kick guests", func(t *testing.T) {
+		s := NewServer()
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+		code := createLobby(t, s, host, guest)
+
+		s.handleMessage(guest, &Message{Type: "lobby_kick", Data: map[string]any{"player_id": "player1"}})
+		if msg := nextOfType(t, guest, "error"); msg.Data["message"] != lobby.ErrNotHost.Error() {
+			t.Errorf("Expected only the host to kick, got %+v", msg)
+		}
+
+		s.handleMessage(host, &Message{Type: "lobby_kick", Data: map[string]any{"player_id": "player2"}})
+		if msg := nextOfType(t, guest, "lobby_kicked"); msg.Data["code"] != code {
+			t.Errorf("Expected the guest to be kicked from %s, got %+v", code, msg)
+		}
+		if l := lobbyOf(nextOfType(t, host, "lobby_updated")); l.Guest != "" {
+			t.Errorf("Expected the guest to be gone, got %+v", l)
+		}
+	})
+
+	t.Run("Hosts change the settings", func(t *testing.T) {
+		s := NewServer()
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+		createLobby(t, s, host, guest)
+
+		s.handleMessage(host, &Message{Type: "lobby_settings", Data: map[string]any{"format": "best_of_5"}})
+		for _, conn := range []*Connection{host, guest} {
+			if l := lobbyOf(nextOfType(t, conn, "lobby_updated")); l.Config.Rounds != 5 {
+				t.Errorf("Expected a best of 5 lobby, got %+v", l.Config)
+			}
+		}
+
+		s.handleMessage(guest, &Message{Type: "lobby_settings", Data: map[string]any{"format": "best_of_3"}})
+		if msg := nextOfType(t, guest, "error"); msg.Data["message"] != lobby.ErrNotHost.Error() {
+			t.Errorf("Expected only the host to change settings, got %+v", msg)
+		}
+		s.handleMessage(host, &Message{Type: "lobby_settings", Data: map[string]any{"format": "best_of"}})
+		nextOfType(t, host, "error")
+	})
+
+	t.Run("Starting seats both players", func(t *testing.T) {
+		s := NewServer()
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+		code := createLobby(t, s, host, guest)
+
+		s.handleMessage(guest, &Message{Type: "start_lobby"})
+		if msg := nextOfType(t, guest, "error"); msg.Data["message"] != lobby.ErrNotHost.Error() {
+			t.Errorf("Expected only the host to start, got %+v", msg)
+		}
+
+		s.handleMessage(host, &Message{Type: "start_lobby"})
+		started := nextOfType(t, host, "lobby_started")
+		if msg := nextOfType(t, guest, "lobby_started"); msg.Data["gameID"] != started.Data["gameID"] || msg.Data["opponent"] != "player1" {
+			t.Errorf("Expected the guest to join the host's game, got %+v", msg)
+		}
+		if s.gameOf(host) != started.Data["gameID"] || s.gameOf(guest) != started.Data["gameID"] {
+			t.Error("Expected both connections to be in the game")
+		}
+		if _, exists := s.lobbies.Get(code); exists {
+			t.Error("Expected the lobby to be removed")
+		}
+	})
+
+	t.Run("Starting without a guest fails", func(t *testing.T) {
+		s := NewServer()
+		host := bufferedConn(s, "player1")
+		createLobby(t, s, host, nil)
+
+		s.handleMessage(host, &Message{Type: "start_lobby"})
+		if msg := nextOfType(t, host, "error"); msg.Data["message"] != lobby.ErrNoGuest.Error() {
+			t.Errorf("Expected a no guest error, got %+v", msg)
+		}
+	})
+
+	t.Run("Starting with a disconnected guest keeps the lobby", func(t *testing.T) {
+		s := NewServer()
+		host := bufferedConn(s, "player1")
+		code := createLobby(t, s, host, nil)
+		if _, err := s.lobbies.Join(code, "player2"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		s.handleMessage(host, &Message{Type: "start_lobby"})
+		if msg := nextOfType(t, host, "error"); msg.Data["message"] != "guest is not connected" {
+			t.Errorf("Expected a guest is not connected error, got %+v", msg)
+		}
+		if l, exists := s.lobbies.Get(code); !exists || l.Guest != "player2" {
+			t.Errorf("Expected the lobby to be left as it was, got %+v, %v", l, exists)
+		}
+
+		s.handleMessage(bufferedConn(s, "player2"), &Message{Type: "join_lobby", Data: map[string]any{"code": code}})
+		nextOfType(t, host, "lobby_updated")
+		s.handleMessage(host, &Message{Type: "start_lobby"})
+		nextOfType(t, host, "lobby_started")
+	})
+
+	t.Run("Expired lobbies are closed", func(t *testing.T) {
+		clock := game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+		s := NewServerWithClock(clock)
+		host, guest := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+		code := createLobby(t, s, host, guest)
+
+		clock.Advance(DefaultLobbyMaxAge + time.Second)
+		s.expireLobbies(DefaultLobbyMaxAge)
+		for _, conn := range []*Connection{host, guest} {
+			if msg := nextOfType(t, conn, "lobby_closed"); msg.Data["code"] != code || msg.Data["reason"] != "expired" {
+				t.Errorf("Expected the lobby to close as expired, got %+v", msg)
+			}
+		}
+	})
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"ldriko/rps-backend/bot"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/matchmaking"
+	"log"
+	"time"
+)
+
+type MatchmakingConfig struct {
+	// Interval is how often queued players are matched.
+	Interval time.Duration
+	// BackfillAfter is how long a player waits for a human opponent before
+	// playing a bot instead. Zero disables bots.
+	BackfillAfter time.Duration
+	// QueueTimeout removes players who waited this long without a match.
+	// Zero keeps them queued forever.
+	QueueTimeout time.Duration
+}
+
+func DefaultMatchmakingConfig() MatchmakingConfig {
+	return MatchmakingConfig{
+		Interval:      time.Second,
+		BackfillAfter: 30 * time.Second,
+		QueueTimeout:  5 * time.Minute,
+	}
+}
+
+// StartMatchmaking runs the background matcher until StopMatchmaking is
+// called.
+func (s *Server) StartMatchmaking(config MatchmakingConfig) {
+	var opts []matchmaking.MatcherOption
+	if config.BackfillAfter > 0 {
+		opts = append(opts, matchmaking.WithBackfill(config.BackfillAfter, s.handleBackfill))
+	}
+	if config.QueueTimeout > 0 {
+		opts = append(opts, matchmaking.WithQueueTimeout(config.QueueTimeout, s.handleQueueTimeout))
+	}
+
+	s.matcher = matchmaking.NewMatcher(s.queue, config.Interval, s.handleMatch, s.broadcastQueueStatus, opts...)
+	s.matcher.Start()
+}
+
+func (s *Server) StopMatchmaking() {
+	if s.matcher != nil {
+		s.matcher.Stop()
+	}
+}
+
+func (s *Server) handleQueueJoin(conn *Connection, data map[string]any) {
+	if s.queue.Contains(conn.playerID) {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": "already in queue"},
+		})
+		return
+	}
+
+	if gameID := s.gameOf(conn); gameID != "" {
+		if gm, exists := s.gm.GetGame(gameID); exists && !gm.IsOver() {
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": "already in a game"},
+			})
+			return
+		}
+	}
+
+	ticket, err := s.ticketFromData(conn.playerID, data)
+	if err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+		return
+	}
+
+	s.queue.AddTicket(ticket)
+	conn.SendMessage(Message{
+		Type: "queue_joined",
+		Data: s.queueStatus(conn.playerID),
+	})
+}
+
+func (s *Server) handleQueueLeave(conn *Connection, _ map[string]any) {
+	if !s.queue.Contains(conn.playerID) {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": "not in queue"},
+		})
+		return
+	}
+
+	s.queue.RemovePlayer(conn.playerID)
+	conn.SendMessage(Message{
+		Type: "queue_left",
+		Data: map[string]any{},
+	})
+}
+
+// ticketFromData builds a match ticket from the optional "region", "format",
+// "rule_set", "ranked" (true by default), "blocked" and "party_size" fields
+// of a queue_join message.
+func (s *Server) ticketFromData(playerID string, data map[string]any) (matchmaking.MatchTicket, error) {
+	player, err := s.players.Get(playerID)
+	if err != nil {
+		return matchmaking.MatchTicket{}, err
+	}
+	ticket := matchmaking.MatchTicket{
+		Player: &player,
+		Ranked: true,
+	}
+
+	if region, ok := data["region"].(string); ok {
+		ticket.Region = region
+	}
+	if format, ok := data["format"].(string); ok {
+		if _, err := game.ParseMatchFormat(format); err != nil {
+			return matchmaking.MatchTicket{}, err
+		}
+		ticket.Mode = format
+	}
+	if ruleSet, ok := data["rule_set"].(string); ok {
+		if _, exists := game.GetRuleSet(ruleSet); !exists {
+			return matchmaking.MatchTicket{}, fmt.Errorf("unknown rule set %q", ruleSet)
+		}
+		ticket.RuleSet = ruleSet
+	}
+	if ranked, ok := data["ranked"].(bool); ok {
+		ticket.Ranked = ranked
+	}
+	if blocked, ok := data["blocked"].([]any); ok {
+		for _, id := range blocked {
+			if id, ok := id.(string); ok {
+				ticket.Blocked = append(ticket.Blocked, id)
+			}
+		}
+	}
+	if partySize, ok := data["party_size"].(float64); ok {
+		if partySize != 1 {
+			return matchmaking.MatchTicket{}, errors.New("only parties of one are supported")
+		}
+		ticket.PartySize = int(partySize)
+	}
+
+	return ticket, nil
+}
+
+// matchConfigFromTicket builds the config of a matched game from the merged
+// tickets of both players.
+func matchConfigFromTicket(ticket matchmaking.MatchTicket) (game.MatchConfig, error) {
+	config := game.DefaultMatchConfig()
+	if ticket.Mode != "" {
+		var err error
+		config, err = game.ParseMatchFormat(ticket.Mode)
+		if err != nil {
+			return game.MatchConfig{}, err
+		}
+	}
+	if ticket.RuleSet != "" {
+		config.RuleSet = ticket.RuleSet
+	}
+	config.Ranked = ticket.Ranked
+
+	return config, config.Validate()
+}
+
+// handleMatch creates a game for two matched players and seats both of their
+// connections in it. A player who disconnected in the meantime is dropped and
+// the other one goes back into the queue, keeping their place.
+func (s *Server) handleMatch(match matchmaking.Match) {
+	p1, p2 := match.P1, match.P2
+
+	s.mu.RLock()
+	conn1, ok1 := s.conns[p1.ID]
+	conn2, ok2 := s.conns[p2.ID]
+	s.mu.RUnlock()
+
+	if !ok1 || !ok2 {
+		if ok1 {
+			s.queue.Requeue(match.P1Ticket, match.P1JoinedAt)
+		}
+		if ok2 {
+			s.queue.Requeue(match.P2Ticket, match.P2JoinedAt)
+		}
+		return
+	}
+
+	// Both players have left the queue by now, so they are told when their
+	// game cannot be created rather than left waiting for it.
+	config, err := matchConfigFromTicket(match.Settings())
+	if err != nil {
+		log.Printf("invalid match settings for %s and %s: %v", p1.ID, p2.ID, err)
+		sendMatchError(err, conn1, conn2)
+		return
+	}
+
+	if _, err := s.startGame(conn1, conn2, config, "match_found"); err != nil {
+		log.Printf("failed to create a game for %s and %s: %v", p1.ID, p2.ID, err)
+		sendMatchError(err, conn1, conn2)
+	}
+}
+
+// sendMatchError tells players taken out of the queue that their match could
+// not be started.
+func sendMatchError(err error, conns ...*Connection) {
+	for _, conn := range conns {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
+	}
+}
+
+// startGame creates a game between two connected players, seats both
+// connections in it and tells each of them with a msgType message.
+func (s *Server) startGame(conn1, conn2 *Connection, config game.MatchConfig, msgType string) (*game.Game, error) {
+	gm, err := s.gm.CreateGame(conn1.playerID, conn2.playerID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.addPlayerToGame(conn1, gm.ID)
+	s.addPlayerToGame(conn2, gm.ID)
+
+	for _, conn := range []*Connection{conn1, conn2} {
+		opponent := conn2.playerID
+		if conn == conn2 {
+			opponent = conn1.playerID
+		}
+
+		conn.SendMessage(Message{
+			Type: msgType,
+			Data: map[string]any{
+				"gameID":   gm.ID,
+				"opponent": opponent,
+				"seat":     gm.SeatOf(conn.playerID),
+				"game":     gm.ViewFor(conn.playerID),
+			},
+		})
+	}
+
+	gm.SetPlayerConnected(conn1.playerID, true)
+	gm.SetPlayerConnected(conn2.playerID, true)
+	return gm, nil
+}
+
+// handleBackfill starts a game against a bot for a player nobody was matched
+// with in time. Bot games are never ranked.
+func (s *Server) handleBackfill(qp matchmaking.QueuedPlayer) {
+	s.mu.RLock()
+	conn, connected := s.conns[qp.Player.ID]
+	s.mu.RUnlock()
+
+	if !connected {
+		return
+	}
+
+	config, err := matchConfigFromTicket(qp.Ticket)
+	if err != nil {
+		log.Printf("invalid match settings for %s: %v", qp.Player.ID, err)
+		sendMatchError(err, conn)
+		return
+	}
+	config.Ranked = false
+	config.VsBot = true
+
+	gm, err := s.gm.CreateGame(qp.Player.ID, "", config)
+	if err != nil {
+		log.Printf("failed to create a bot game for %s: %v", qp.Player.ID, err)
+		sendMatchError(err, conn)
+		return
+	}
+
+	b := bot.New("bot-"+s.botIDs.Generate(), bot.DefaultStrategy())
+	s.addPlayerToGame(conn, gm.ID)
+
+	if _, err := b.Join(gm, s.gm.Events()); err != nil {
+		log.Printf("failed to seat a bot in game %s: %v", gm.ID, err)
+		s.removePlayerFromGame(conn, gm.ID)
+		s.gm.RemoveGame(gm.ID)
+		sendMatchError(err, conn)
+		return
+	}
+
+	conn.SendMessage(Message{
+		Type: "match_found",
+		Data: map[string]any{
+			"gameID":   gm.ID,
+			"opponent": b.ID,
+			"bot":      true,
+			"seat":     gm.SeatOf(conn.playerID),
+			"game":     gm.ViewFor(conn.playerID),
+		},
+	})
+
+	gm.SetPlayerConnected(conn.playerID, true)
+}
+
+func (s *Server) handleQueueTimeout(qp matchmaking.QueuedPlayer) {
+	s.sendToPlayer(qp.Player.ID, Message{
+		Type: "queue_timeout",
+		Data: map[string]any{
+			"waitedSeconds": int(s.clock.Now().Sub(qp.JoinedAt) / time.Second),
+		},
+	})
+}
+
+// broadcastQueueStatus tells every waiting player where they stand.
+func (s *Server) broadcastQueueStatus() {
+	for _, qp := range s.queue.Snapshot() {
+		s.sendToPlayer(qp.Player.ID, Message{
+			Type: "queue_status",
+			Data: s.queueStatus(qp.Player.ID),
+		})
+	}
+}
+
+func (s *Server) queueStatus(playerID string) map[string]any {
+	position, _ := s.queue.Position(playerID)
+	return map[string]any{
+		"rating":     s.players.Rating(playerID).Rating,
+		"position":   position,
+		"queueSize":  s.queue.GetQueueSize(),
+		"etaSeconds": int(s.queue.EstimateWait(playerID).Round(time.Second) / time.Second),
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/players"
+	"ldriko/rps-backend/rating"
+	"log"
+	"net/http"
+)
+
+// profile is the part of a player anyone may see.
+type profile struct {
+	ID        string        `json:"id"`
+	Username  string        `json:"username"`
+	CreatedAt int64         `json:"createdAt"`
+	Rating    rating.Rating `json:"rating"`
+}
+
+func publicProfile(player models.Player) profile {
+	return profile{
+		ID:        player.ID,
+		Username:  player.Username,
+		CreatedAt: player.CreatedAt,
+		Rating:    player.Rating,
+	}
+}
+
+// HandleRegister serves POST /players, creating a player from a JSON body
+// with "username", "password" and an optional "email".
+func (s *Server) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	player, err := s.players.Register(body.Username, body.Email, body.Password)
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, player)
+}
+
+// HandleGetPlayer serves GET /players/{id}, the player's public profile.
+func (s *Server) HandleGetPlayer(w http.ResponseWriter, r *http.Request) {
+	player, err := s.players.Get(r.PathValue("id"))
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, publicProfile(player))
+}
+
+// HandleUpdatePlayer serves PATCH /players/{id}, changing the "username",
+// "email" and "password" fields present in the JSON body. Players can only
+// update their own profile.
+func (s *Server) HandleUpdatePlayer(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	if claims.Subject != r.PathValue("id") {
+		http.Error(w, "cannot update another player", http.StatusForbidden)
+		return
+	}
+
+	var update players.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	player, err := s.players.UpdateProfile(r.PathValue("id"), update)
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, player)
+}
+
+func writePlayerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, players.ErrPlayerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, players.ErrUsernameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, players.ErrInvalidUsername), errors.Is(err, players.ErrInvalidEmail), errors.Is(err, players.ErrInvalidPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("player request failed: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
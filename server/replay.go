@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/game"
+	"log"
+	"net/http"
+)
+
+type replayResponse struct {
+	GameID string            `json:"gameID"`
+	Events []json.RawMessage `json:"events"`
+	Result game.GameResult   `json:"result"`
+}
+
+// HandleReplay serves GET /games/{id}/replay, the event timeline of a
+// finished game. Games still being played are refused so the timeline cannot
+// leak moves to the opponent.
+func (s *Server) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	events, err := s.gm.EventLog(id)
+	if errors.Is(err, game.ErrGameNotFound) {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("failed to load events of game %s: %v", id, err)
+		http.Error(w, "failed to load game", http.StatusInternalServerError)
+		return
+	}
+
+	replayed, err := game.Replay(events)
+	if err != nil {
+		log.Printf("failed to replay game %s: %v", id, err)
+		http.Error(w, "failed to replay game", http.StatusInternalServerError)
+		return
+	}
+	if !replayed.IsOver() {
+		http.Error(w, "game is still in progress", http.StatusConflict)
+		return
+	}
+
+	response := replayResponse{
+		GameID: id,
+		Events: make([]json.RawMessage, len(events)),
+		Result: replayed.Result(),
+	}
+	for i, event := range events {
+		if response.Events[i], err = game.MarshalEvent(event); err != nil {
+			log.Printf("failed to encode %s event of game %s: %v", event.Type(), id, err)
+			http.Error(w, "failed to encode replay", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
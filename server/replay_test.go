@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"ldriko/rps-backend/game"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	store := game.NewMemoryStore()
+	s := NewServerWithManager(game.NewManager(game.WithStore(store)))
+
+	replay := func(s *Server, id string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /games/{id}/replay", s.HandleReplay)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/games/"+id+"/replay", nil))
+		return w
+	}
+
+	// finished plays a game to the end, player1 winning every round.
+	finished := func(t *testing.T) *game.Game {
+		t.Helper()
+
+		gm, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+		for !gm.IsOver() {
+			if _, err := gm.NewRound(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := gm.PlayRound(game.Rock, game.Scissors); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		return gm
+	}
+
+	// assertReplay checks the response is the whole timeline of gm.
+	assertReplay := func(t *testing.T, w *httptest.ResponseRecorder, gm *game.Game) {
+		t.Helper()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200 OK, got %d: %s", w.Code, w.Body)
+		}
+		var response replayResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.GameID != gm.ID || response.Result.Winner != "player1" || response.Result.P1Wins != gm.Result().P1Wins {
+			t.Errorf("Expected player1 to have won game %s, got %+v", gm.ID, response)
+		}
+		if len(response.Events) != len(gm.EventLog()) {
+			t.Fatalf("Expected %d events, got %d", len(gm.EventLog()), len(response.Events))
+		}
+		for i, data := range response.Events {
+			event, err := game.UnmarshalEvent(data)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if want := gm.EventLog()[i].Type(); event.Type() != want {
+				t.Errorf("Expected event %d to be %s, got %s", i, want, event.Type())
+			}
+		}
+	}
+
+	t.Run("Unknown games are not found", func(t *testing.T) {
+		if w := replay(s, "no-such-game"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Games in progress are refused", func(t *testing.T) {
+		gm, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+		gm.NewRound()
+		if w := replay(s, gm.ID); w.Code != http.StatusConflict {
+			t.Errorf("Expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Finished games are replayed", func(t *testing.T) {
+		gm := finished(t)
+		assertReplay(t, replay(s, gm.ID), gm)
+	})
+
+	t.Run("Dropped games are loaded from the store", func(t *testing.T) {
+		gm := finished(t)
+		if err := s.gm.RemoveGame(gm.ID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertReplay(t, replay(s, gm.ID), gm)
+
+		restarted := NewServerWithManager(game.NewManager(game.WithStore(store)))
+		assertReplay(t, replay(restarted, gm.ID), gm)
+	})
+}
@@ -2,76 +2,200 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"ldriko/rps-backend/auth"
 	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/lobby"
+	"ldriko/rps-backend/matchmaking"
+	"ldriko/rps-backend/players"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		// In production, implement proper origin checking
-		return true
-	},
-}
-
 type Connection struct {
 	ws       *websocket.Conn
 	send     chan []byte
 	playerID string
-	gameID   string
-	mu       sync.Mutex
+	// gameID is the game the connection has joined. It is guarded by the
+	// server's mu, since matches seat players from other goroutines; read it
+	// through Server.gameOf.
+	gameID string
+	// expiry closes the connection when its access token expires.
+	expiry game.Timer
+	// closed is set once send has been closed, after which nothing more is
+	// sent.
+	closed bool
+	// session numbers the messages sent to the player and keeps them for a
+	// resume. Without one, messages are sent as they are.
+	session *session
+	mu      sync.Mutex
 }
 
 type Message struct {
 	Type string         `json:"type"`
 	Data map[string]any `json:"data"`
+	// Seq numbers the messages the server sends in a session. Clients
+	// resume from the last one they saw.
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 type Server struct {
 	gm        *game.Manager
+	clock     game.Clock
+	queue     *matchmaking.MatchmakingQueue
+	matcher   *matchmaking.Matcher
+	players   *players.Service
+	tokens    *auth.Issuer
+	config    Config
+	upgrader  *websocket.Upgrader
+	lobbies   *lobby.Manager
+	botIDs    game.UUIDGenerator
 	conns     map[string]*Connection
 	gameConns map[string][]*Connection
-	mu        sync.RWMutex
+	sessions  map[string]*session
+	// graceTimers forfeit the games of players who left, unless they come
+	// back in time.
+	graceTimers map[graceKey]game.Timer
+	mu          sync.RWMutex
+
+	// sweeperStop stops the lobby sweeper started by StartLobbySweeper.
+	sweeperStop chan struct{}
 }
 
 func NewServer() *Server {
-	return &Server{
-		gm:        game.NewManager(),
-		conns:     make(map[string]*Connection),
-		gameConns: make(map[string][]*Connection),
+	return NewServerWithClock(game.RealClock{})
+}
+
+func NewServerWithClock(clock game.Clock) *Server {
+	return NewServerWithManager(game.NewManager(game.WithClock(clock)))
+}
+
+type Option func(*Server)
+
+// WithPlayers makes the server look players up in service. By default
+// players are kept in memory.
+func WithPlayers(service *players.Service) Option {
+	return func(s *Server) {
+		s.players = service
 	}
 }
 
+// WithConfig replaces DefaultConfig as the server's websocket policy.
+func WithConfig(config Config) Option {
+	return func(s *Server) {
+		s.config = config
+	}
+}
+
+// WithTokens makes the server accept the tokens of issuer. By default tokens
+// are signed with a random secret and do not survive a restart.
+func WithTokens(issuer *auth.Issuer) Option {
+	return func(s *Server) {
+		s.tokens = issuer
+	}
+}
+
+// NewServerWithManager serves the games of gm, using its clock for round
+// timers, lobby expiry and matchmaking waits.
+func NewServerWithManager(gm *game.Manager, opts ...Option) *Server {
+	clock := gm.Clock()
+	tokens, _ := auth.NewIssuer(auth.RandomSecret(), auth.WithClock(clock))
+	s := &Server{
+		gm:          gm,
+		clock:       clock,
+		queue:       matchmaking.NewQueue(matchmaking.WithClock(clock)),
+		players:     players.NewService(players.NewMemoryRepository(), players.WithClock(clock)),
+		tokens:      tokens,
+		config:      DefaultConfig(),
+		lobbies:     lobby.NewManager(lobby.WithClock(clock)),
+		botIDs:      &game.DefaultUUIDGenerator{},
+		conns:       make(map[string]*Connection),
+		gameConns:   make(map[string][]*Connection),
+		sessions:    make(map[string]*session),
+		graceTimers: make(map[graceKey]game.Timer),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.upgrader = s.config.upgrader()
+	s.gm.Events().Subscribe(s.handleGameEvent)
+	return s
+}
+
+// Restore brings back the unfinished games saved by the manager's store and
+// restarts the timers of their rounds. Nobody is connected to a restored
+// game, so its players get a fresh grace period to come back.
+func (s *Server) Restore() error {
+	games, err := s.gm.LoadUnfinishedGames()
+	if err != nil {
+		return err
+	}
+
+	for _, gm := range games {
+		view := gm.SpectatorView()
+		if round := view.CurrentRound; round != nil {
+			s.scheduleRoundTimeout(gm.ID, round.Number, round.Deadline)
+		}
+		if !view.P1Connected {
+			s.startGracePeriod(gm, view.P1, s.clock.Now())
+		}
+		if !view.P2Connected {
+			s.startGracePeriod(gm, view.P2, s.clock.Now())
+		}
+	}
+	log.Printf("restored %d unfinished games", len(games))
+	return nil
+}
+
 func (s *Server) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	playerID := r.URL.Query().Get("player_id")
-	if playerID == "" {
-		http.Error(w, "player_id is required", http.StatusBadRequest)
+	claims, err := s.authenticate(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	player, err := s.players.Get(claims.Subject)
+	if errors.Is(err, players.ErrPlayerNotFound) {
+		writeAuthError(w, errors.New("unknown player"))
+		return
+	} else if err != nil {
+		log.Printf("failed to look up player %s: %v", claims.Subject, err)
+		http.Error(w, "failed to look up player", http.StatusInternalServerError)
 		return
 	}
 
-	ws, err := upgrader.Upgrade(w, r, nil)
+	// Upgrade replies to the client itself when it fails.
+	ws, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("websocket upgrade error: %v", err)
-		http.Error(w, "websocket upgrade failed", http.StatusInternalServerError)
 		return
 	}
+	if s.config.MaxMessageSize > 0 {
+		ws.SetReadLimit(s.config.MaxMessageSize)
+	}
 
 	conn := &Connection{
 		ws:       ws,
 		send:     make(chan []byte, 256),
-		playerID: playerID,
+		playerID: player.ID,
 	}
 
-	s.registerConnection(conn)
+	if s.registerConnection(conn, resumeRequestFrom(r.URL.Query())) {
+		s.setConnected(s.gameOf(conn), conn.playerID, true)
+	}
+	s.scheduleSessionExpiry(conn, claims.Expiry())
 
 	go conn.readMessages(s)
-	go conn.writeMessages()
+	go conn.writeMessages(s.config)
 }
 
-func (s *Server) registerConnection(conn *Connection) {
+// registerConnection makes conn the player's connection, resuming their
+// session when req allows it. It reports whether the session was resumed.
+func (s *Server) registerConnection(conn *Connection, req resumeRequest) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -80,28 +204,56 @@ func (s *Server) registerConnection(conn *Connection) {
 	}
 
 	s.conns[conn.playerID] = conn
-	log.Printf("player %s connected", conn.playerID)
+	resumed := s.attachSession(conn, req)
+	log.Printf("player %s connected (resumed: %v)", conn.playerID, resumed)
+	return resumed
 }
 
 func (s *Server) unregisterConnection(conn *Connection) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if s.conns[conn.playerID] == conn {
+	gameID := conn.gameID
+	replacement := s.conns[conn.playerID]
+	current := replacement == conn
+	if current {
 		delete(s.conns, conn.playerID)
 	}
-
-	s.removePlayerFromGame(conn, conn.gameID)
-
-	if conn.gameID != "" {
-		if game, exists := s.gm.GetGame(conn.gameID); exists {
-			game.SetPlayerConnected(conn.playerID, false)
+	// A resumed session moves the game over to the new connection.
+	takenOver := !current && replacement != nil && gameID != "" && replacement.gameID == gameID
+	s.mu.Unlock()
+
+	if !current {
+		// The player has already connected again. Unless the new connection
+		// took the game over, they are gone from it until they rejoin.
+		if gameID != "" {
+			s.removePlayerFromGame(conn, gameID)
+			if !takenOver {
+				s.setConnected(gameID, conn.playerID, false)
+			}
 		}
+		log.Printf("player %s replaced their connection", conn.playerID)
+		return
 	}
 
+	s.queue.RemovePlayer(conn.playerID)
+	s.leaveLobby(conn.playerID)
+
+	// The connection stays in its game until the session expires, so what
+	// the player misses is kept for them to resume.
+	s.detachSession(conn)
+	s.setConnected(gameID, conn.playerID, false)
+
 	log.Printf("player %s disconnected from game", conn.playerID)
 }
 
+// setConnected marks the player connected to or gone from the game, if there
+// is one. The game publishes events to the server, so it must be updated
+// without holding s.mu.
+func (s *Server) setConnected(gameID, playerID string, connected bool) {
+	if gm, exists := s.gm.GetGame(gameID); exists {
+		gm.SetPlayerConnected(playerID, connected)
+	}
+}
+
 func (s *Server) addPlayerToGame(conn *Connection, gameID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -112,14 +264,37 @@ func (s *Server) addPlayerToGame(conn *Connection, gameID string) {
 		return
 	}
 
+	if conn.gameID != "" && conn.gameID != gameID {
+		s.removeConnFromGame(conn, conn.gameID)
+	}
+
+	for _, c := range s.gameConns[gameID] {
+		if c == conn {
+			return
+		}
+	}
+
 	conn.gameID = gameID
 	s.gameConns[gameID] = append(s.gameConns[gameID], conn)
 }
 
+// gameOf returns the game the connection has joined, if any.
+func (s *Server) gameOf(conn *Connection) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return conn.gameID
+}
+
 func (s *Server) removePlayerFromGame(conn *Connection, gameID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.removeConnFromGame(conn, gameID)
+}
+
+// removeConnFromGame must be called with s.mu held.
+func (s *Server) removeConnFromGame(conn *Connection, gameID string) {
 	if conn.gameID == "" {
 		log.Printf("trying to remove from game but conn.gameID is empty")
 		return
@@ -141,12 +316,21 @@ func (s *Server) removePlayerFromGame(conn *Connection, gameID string) {
 	conn.gameID = ""
 }
 
+// readMessages handles the client's messages until the connection fails or
+// stays silent for longer than the pong timeout. Every way a connection ends
+// comes through here, so the player is always unregistered.
 func (conn *Connection) readMessages(s *Server) {
 	defer func() {
 		s.unregisterConnection(conn)
 		conn.close()
 	}()
 
+	conn.extendReadDeadline(s.config.PongTimeout)
+	conn.ws.SetPongHandler(func(string) error {
+		conn.extendReadDeadline(s.config.PongTimeout)
+		return nil
+	})
+
 	for {
 		var msg Message
 		err := conn.ws.ReadJSON(&msg)
@@ -157,56 +341,158 @@ func (conn *Connection) readMessages(s *Server) {
 			break
 		}
 
+		conn.extendReadDeadline(s.config.PongTimeout)
 		s.handleMessage(conn, &msg)
 	}
 }
 
-func (conn *Connection) writeMessages() {
+func (conn *Connection) extendReadDeadline(timeout time.Duration) {
+	if timeout > 0 {
+		conn.ws.SetReadDeadline(time.Now().Add(timeout))
+	}
+}
+
+// writeMessages sends queued messages and pings until send is closed or a
+// write fails. Closing the socket on the way out ends readMessages too.
+func (conn *Connection) writeMessages(config Config) {
 	defer conn.ws.Close()
 
-	for message := range conn.send {
-		err := conn.ws.WriteMessage(websocket.TextMessage, message)
-		if err != nil {
-			log.Printf("error writing message: %v", err)
-			return
+	var ping <-chan time.Time
+	if config.PingInterval > 0 {
+		ticker := time.NewTicker(config.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case message, ok := <-conn.send:
+			conn.extendWriteDeadline(config.WriteTimeout)
+			if !ok {
+				conn.ws.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.ws.WriteMessage(websocket.TextMessage, message); err != nil {
+				log.Printf("error writing message: %v", err)
+				return
+			}
+		case <-ping:
+			conn.extendWriteDeadline(config.WriteTimeout)
+			if err := conn.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("error pinging player %s: %v", conn.playerID, err)
+				return
+			}
 		}
 	}
+}
 
-	conn.ws.WriteMessage(websocket.CloseMessage, []byte{})
+func (conn *Connection) extendWriteDeadline(timeout time.Duration) {
+	if timeout > 0 {
+		conn.ws.SetWriteDeadline(time.Now().Add(timeout))
+	}
 }
 
+// close stops sending to the connection. It is safe to call more than once.
 func (conn *Connection) close() {
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
 
-	close(conn.send)
+	if conn.expiry != nil {
+		conn.expiry.Stop()
+	}
+	if !conn.closed {
+		conn.closed = true
+		close(conn.send)
+	}
 }
 
+// SendMessage sends msg to the client. Messages of a session are recorded
+// even once the connection is closed, for the player to resume.
 func (conn *Connection) SendMessage(msg Message) {
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
 
+	var data []byte
+	var err error
+	if conn.session != nil {
+		data, err = conn.session.record(msg)
+	} else {
+		data, err = json.Marshal(msg)
+	}
+	if err != nil {
+		log.Printf("error marshalling message: %v", err)
+		return
+	}
+	conn.enqueue(data)
+}
+
+// sendUnrecorded sends msg outside the connection's session, unnumbered.
+func (conn *Connection) sendUnrecorded(msg Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		log.Printf("error marshalling message: %v", err)
 		return
 	}
+	conn.sendEncoded([][]byte{data})
+}
+
+// sendEncoded sends messages that are already encoded.
+func (conn *Connection) sendEncoded(messages [][]byte) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	for _, data := range messages {
+		conn.enqueue(data)
+	}
+}
+
+// enqueue must be called with conn.mu held.
+func (conn *Connection) enqueue(data []byte) {
+	if conn.closed {
+		return
+	}
 
 	select {
 	case conn.send <- data:
 	default:
+		// A client this far behind is dropped rather than allowed to hold
+		// up the game; closing send makes writeMessages hang up.
+		log.Printf("send buffer of player %s is full, disconnecting", conn.playerID)
+		conn.closed = true
 		close(conn.send)
 	}
 }
 
 func (s *Server) handleMessage(conn *Connection, msg *Message) {
 	switch msg.Type {
+	case "authenticate":
+		s.handleAuthenticate(conn, msg.Data)
 	case "join_game":
 		s.handleJoinGame(conn, msg.Data)
 	case "make_move":
 		s.handleMakeMove(conn, msg.Data)
 	case "start_round":
 		s.handleStartRound(conn, msg.Data)
+	case "commit_move":
+		s.handleCommitMove(conn, msg.Data)
+	case "reveal_move":
+		s.handleRevealMove(conn, msg.Data)
+	case "queue_join":
+		s.handleQueueJoin(conn, msg.Data)
+	case "queue_leave":
+		s.handleQueueLeave(conn, msg.Data)
+	case "create_lobby":
+		s.handleCreateLobby(conn, msg.Data)
+	case "join_lobby":
+		s.handleJoinLobby(conn, msg.Data)
+	case "leave_lobby":
+		s.handleLeaveLobby(conn, msg.Data)
+	case "lobby_kick":
+		s.handleLobbyKick(conn, msg.Data)
+	case "lobby_settings":
+		s.handleLobbySettings(conn, msg.Data)
+	case "start_lobby":
+		s.handleStartLobby(conn, msg.Data)
 	default:
 		log.Printf("unknown message type: %s", msg.Type)
 	}
@@ -223,11 +509,41 @@ func (s *Server) handleJoinGame(conn *Connection, data map[string]any) {
 		return
 	}
 
-	game, exists := s.gm.GetGame(gameID)
+	// Moving the connection would leave the player seated in their game
+	// with nobody there to play it.
+	if current := s.gameOf(conn); current != "" && current != gameID {
+		if gm, exists := s.gm.GetGame(current); exists && !gm.IsOver() && gm.SeatOf(conn.playerID) != game.SeatSpectator {
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": "already in a game"},
+			})
+			return
+		}
+	}
+
+	spectate, _ := data["spectate"].(bool)
+
+	gm, exists := s.gm.GetGame(gameID)
 	if !exists {
+		if spectate {
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": "game not found"},
+			})
+			return
+		}
+
+		config, err := matchConfigFromData(data)
+		if err != nil {
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": err.Error()},
+			})
+			return
+		}
+
 		log.Printf("creating new game for player %s", conn.playerID)
-		var err error
-		game, err = s.gm.CreateGame(conn.playerID, "")
+		gm, err = s.gm.CreateGame(conn.playerID, "", config)
 		if err != nil {
 			log.Printf("failed to create a game for player %s: %v", conn.playerID, err)
 			conn.SendMessage(Message{
@@ -238,30 +554,80 @@ func (s *Server) handleJoinGame(conn *Connection, data map[string]any) {
 		}
 	}
 
-	s.addPlayerToGame(conn, game.ID)
-	game.SetPlayerConnected(conn.playerID, true)
+	// Join the game's connections before taking a seat so this connection
+	// also receives the game_ready message if it fills the game.
+	s.addPlayerToGame(conn, gm.ID)
+
+	seat := game.SeatSpectator
+	if !spectate {
+		var err error
+		seat, err = gm.AddPlayer(conn.playerID)
+		if err != nil {
+			s.removePlayerFromGame(conn, gm.ID)
+			conn.SendMessage(Message{
+				Type: "error",
+				Data: map[string]any{"message": err.Error()},
+			})
+			return
+		}
+	}
 
 	conn.SendMessage(Message{
 		Type: "game_joined",
 		Data: map[string]any{
-			"gameID": game.ID,
-			"game":   game,
+			"gameID": gm.ID,
+			"seat":   seat,
+			"game":   gm.ViewFor(conn.playerID),
 		},
 	})
 
-	s.broadcastToGame(gameID, Message{
-		Type: "player_joined",
-		Data: map[string]any{"playerID": conn.playerID},
-	}, conn.playerID)
+	if seat != game.SeatSpectator {
+		gm.SetPlayerConnected(conn.playerID, true)
+	}
+}
+
+// matchConfigFromData builds the config for a new game from the optional
+// "format", "draws_count", "sudden_death", "max_rounds", "rule_set",
+// "move_timeout" (in seconds), "timeout_policy" and "commit_reveal" fields of
+// a join_game message.
+func matchConfigFromData(data map[string]any) (game.MatchConfig, error) {
+	config := game.DefaultMatchConfig()
+
+	if format, ok := data["format"].(string); ok {
+		var err error
+		config, err = game.ParseMatchFormat(format)
+		if err != nil {
+			return game.MatchConfig{}, err
+		}
+	}
+	if drawsCount, ok := data["draws_count"].(bool); ok {
+		config.DrawsCount = drawsCount
+	}
+	if suddenDeath, ok := data["sudden_death"].(bool); ok {
+		config.SuddenDeath = suddenDeath
+	}
+	if maxRounds, ok := data["max_rounds"].(float64); ok {
+		config.MaxRounds = int(maxRounds)
+	}
+	if ruleSet, ok := data["rule_set"].(string); ok {
+		config.RuleSet = ruleSet
+	}
+	if moveTimeout, ok := data["move_timeout"].(float64); ok {
+		config.MoveTimeout = time.Duration(moveTimeout * float64(time.Second))
+	}
+	if policy, ok := data["timeout_policy"].(string); ok {
+		config.TimeoutPolicy = game.TimeoutPolicy(policy)
+	}
+	if commitReveal, ok := data["commit_reveal"].(bool); ok {
+		config.CommitReveal = commitReveal
+	}
+
+	return config, config.Validate()
 }
 
 func (s *Server) handleMakeMove(conn *Connection, data map[string]any) {
-	gameID := conn.gameID
-	if gameID == "" {
-		conn.SendMessage(Message{
-			Type: "error",
-			Data: map[string]any{"message": "not in a game"},
-		})
+	gm, ok := s.currentGame(conn)
+	if !ok {
 		return
 	}
 
@@ -274,73 +640,111 @@ func (s *Server) handleMakeMove(conn *Connection, data map[string]any) {
 		return
 	}
 
-	gm, exists := s.gm.GetGame(gameID)
-	if !exists {
+	if _, err := gm.SubmitMove(conn.playerID, game.Move(moveStr)); err != nil {
 		conn.SendMessage(Message{
 			Type: "error",
-			Data: map[string]any{"message": "game not found"},
+			Data: map[string]any{"message": err.Error()},
 		})
+	}
+}
+
+func (s *Server) handleStartRound(conn *Connection, _ map[string]any) {
+	gm, ok := s.currentGame(conn)
+	if !ok {
 		return
 	}
 
-	if gm.CurrentRound == nil {
+	if _, err := gm.NewRound(); err != nil {
 		conn.SendMessage(Message{
 			Type: "error",
-			Data: map[string]any{"message": "no active round"},
+			Data: map[string]any{"message": err.Error()},
 		})
+	}
+}
+
+func (s *Server) scheduleRoundTimeout(gameID string, number int, deadline time.Time) {
+	if deadline.IsZero() {
+		return
+	}
+
+	s.clock.AfterFunc(deadline.Sub(s.clock.Now()), func() {
+		s.handleRoundTimeout(gameID, number)
+	})
+}
+
+func (s *Server) handleRoundTimeout(gameID string, number int) {
+	gm, exists := s.gm.GetGame(gameID)
+	if !exists {
 		return
 	}
 
-	move, err := game.ParseMove(moveStr)
+	expired, err := gm.ExpireRound(number)
 	if err != nil {
+		log.Printf("failed to expire round %d of game %s: %v", number, gameID, err)
+		return
+	}
+	if expired {
+		log.Printf("round %d of game %s timed out", number, gameID)
+	}
+}
+
+func (s *Server) handleCommitMove(conn *Connection, data map[string]any) {
+	gm, ok := s.currentGame(conn)
+	if !ok {
+		return
+	}
+
+	commitment, ok := data["commitment"].(string)
+	if !ok {
 		conn.SendMessage(Message{
 			Type: "error",
-			Data: map[string]any{"message": "invalid move"},
+			Data: map[string]any{"message": "invalid commitment"},
 		})
 		return
 	}
 
-	switch conn.playerID {
-	case gm.P1:
-		gm.CurrentRound.P1 = move
-	case gm.P2:
-		gm.CurrentRound.P2 = move
-	default:
+	if _, err := gm.CommitMove(conn.playerID, commitment); err != nil {
 		conn.SendMessage(Message{
 			Type: "error",
-			Data: map[string]any{"message": "not a player in this game"},
+			Data: map[string]any{"message": err.Error()},
 		})
+	}
+}
+
+func (s *Server) handleRevealMove(conn *Connection, data map[string]any) {
+	gm, ok := s.currentGame(conn)
+	if !ok {
 		return
 	}
 
-	if gm.CurrentRound.P1.IsValidMove() && gm.CurrentRound.P2.IsValidMove() {
-		err := gm.PlayRound(gm.CurrentRound.P1, gm.CurrentRound.P2)
-		if err != nil {
-			conn.SendMessage(Message{
-				Type: "error",
-				Data: map[string]any{"message": err.Error()},
-			})
-			return
-		}
+	moveStr, ok := data["move"].(string)
+	if !ok {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": "invalid move"},
+		})
+		return
+	}
+	nonce, _ := data["nonce"].(string)
 
-		s.broadcastToGame(gameID, Message{
-			Type: "round_played",
-			Data: map[string]any{
-				"round": gm.Rounds[len(gm.Rounds)-1],
-				"game":  gm,
-			},
-		}, "")
+	if _, err := gm.RevealMove(conn.playerID, game.Move(moveStr), nonce); err != nil {
+		conn.SendMessage(Message{
+			Type: "error",
+			Data: map[string]any{"message": err.Error()},
+		})
 	}
 }
 
-func (s *Server) handleStartRound(conn *Connection, _ map[string]any) {
-	gameID := conn.gameID
+// currentGame looks up the game the connection has joined, replying with an
+// error when there is none.
+func (s *Server) currentGame(conn *Connection) (*game.Game, bool) {
+	gameID := s.gameOf(conn)
 	if gameID == "" {
 		conn.SendMessage(Message{
 			Type: "error",
 			Data: map[string]any{"message": "not in a game"},
 		})
-		return
+		return nil, false
 	}
 
 	gm, exists := s.gm.GetGame(gameID)
@@ -349,25 +753,9 @@ func (s *Server) handleStartRound(conn *Connection, _ map[string]any) {
 			Type: "error",
 			Data: map[string]any{"message": "game not found"},
 		})
-		return
-	}
-
-	_, err := gm.NewRound()
-	if err != nil {
-		conn.SendMessage(Message{
-			Type: "error",
-			Data: map[string]any{"message": err.Error()},
-		})
-		return
+		return nil, false
 	}
-
-	s.broadcastToGame(gameID, Message{
-		Type: "round_started",
-		Data: map[string]any{
-			"round": gm.CurrentRound,
-			"game":  gm,
-		},
-	}, "")
+	return gm, true
 }
 
 func (s *Server) broadcastToGame(gameID string, msg Message, excludePlayerID string) {
@@ -386,6 +774,21 @@ func (s *Server) broadcastToGame(gameID string, msg Message, excludePlayerID str
 	}
 }
 
+// broadcastGameView sends a message to every connection in the game, built
+// from that recipient's own view of the game so nobody sees the opponent's
+// pending move.
+func (s *Server) broadcastGameView(gm *game.Game, msgType string, build func(view game.GameView) map[string]any) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, conn := range s.gameConns[gm.ID] {
+		conn.SendMessage(Message{
+			Type: msgType,
+			Data: build(gm.ViewFor(conn.playerID)),
+		})
+	}
+}
+
 func (s *Server) sendToPlayer(playerID string, msg Message) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -0,0 +1,383 @@
+package server
+
+import (
+	"fmt"
+	"ldriko/rps-backend/game"
+	"ldriko/rps-backend/game/models"
+	"ldriko/rps-backend/matchmaking"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func isConnected(s *Server, playerID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.conns[playerID]
+	return exists
+}
+
+func TestHeartbeat(t *testing.T) {
+	config := DefaultConfig()
+	config.PingInterval = 20 * time.Millisecond
+	config.PongTimeout = 60 * time.Millisecond
+
+	dial := func(t *testing.T, url, token string) *websocket.Conn {
+		t.Helper()
+
+		ws, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		t.Cleanup(func() { ws.Close() })
+		return ws
+	}
+
+	t.Run("Responsive clients stay connected", func(t *testing.T) {
+		s, url, token := newTestServer(t, config)
+		ws := dial(t, url, token)
+
+		// Reading answers the server's pings.
+		go func() {
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		waitFor(t, func() bool { return isConnected(s, "player1") })
+		time.Sleep(3 * config.PongTimeout)
+		if !isConnected(s, "player1") {
+			t.Error("Expected a client answering pings to stay connected")
+		}
+	})
+
+	t.Run("Silent clients are dropped", func(t *testing.T) {
+		s, url, token := newTestServer(t, config)
+		game, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+
+		ws := dial(t, url, token)
+		ws.WriteJSON(Message{Type: "join_game", Data: map[string]any{"game_id": game.ID}})
+		waitFor(t, func() bool { return game.SpectatorView().P1Connected })
+
+		// The client never reads, so it never answers a ping.
+		waitFor(t, func() bool { return !isConnected(s, "player1") })
+		if game.SpectatorView().P1Connected {
+			t.Error("Expected the dropped player to be marked disconnected")
+		}
+	})
+}
+
+func TestSendMessageToSlowClient(t *testing.T) {
+	conn := &Connection{send: make(chan []byte, 1), playerID: "player1"}
+
+	conn.SendMessage(Message{Type: "one"})
+	conn.SendMessage(Message{Type: "two"})
+	if !conn.closed {
+		t.Fatal("Expected a full send buffer to close the connection")
+	}
+
+	// Neither sending to nor closing a closed connection may panic.
+	conn.SendMessage(Message{Type: "three"})
+	conn.close()
+}
+
+func TestDisconnectGracePeriod(t *testing.T) {
+	// start seats player1, connected through the returned websocket, and
+	// player2, who is only marked connected, in a new game.
+	start := func(t *testing.T) (*Server, *game.Game, *websocket.Conn, *game.MockClock) {
+		t.Helper()
+
+		clock := game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+		s, url, token := newTestServer(t, DefaultConfig(), game.WithClock(clock))
+		gm, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+
+		ws, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		t.Cleanup(func() { ws.Close() })
+		ws.WriteJSON(Message{Type: "join_game", Data: map[string]any{"game_id": gm.ID}})
+		readUntil(t, ws, "game_joined")
+		gm.SetPlayerConnected("player2", true)
+		return s, gm, ws, clock
+	}
+
+	hasGracePeriod := func(s *Server, gameID, playerID string) bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		_, exists := s.graceTimers[graceKey{gameID: gameID, playerID: playerID}]
+		return exists
+	}
+
+	t.Run("Opponents who do not come back forfeit", func(t *testing.T) {
+		s, gm, ws, clock := start(t)
+		gm.SetPlayerConnected("player2", false)
+
+		notice := readUntil(t, ws, "opponent_disconnected")
+		deadline := clock.Now().Add(DefaultConfig().DisconnectGracePeriod).Format(time.RFC3339)
+		if notice.Data["playerID"] != "player2" || notice.Data["deadline"] != deadline {
+			t.Errorf("Expected player2 to have until %s, got %+v", deadline, notice)
+		}
+
+		clock.Advance(DefaultConfig().DisconnectGracePeriod)
+		over := readUntil(t, ws, "game_over")
+		if over.Data["winner"] != "player1" || over.Data["reason"] != game.EndReasonAbandoned {
+			t.Errorf("Expected player1 to win by abandonment, got %+v", over.Data)
+		}
+		if hasGracePeriod(s, gm.ID, "player2") {
+			t.Error("Expected the grace period to be cleared once the game is over")
+		}
+	})
+
+	t.Run("Opponents who come back keep playing", func(t *testing.T) {
+		_, gm, ws, clock := start(t)
+		gm.SetPlayerConnected("player2", false)
+		gm.SetPlayerConnected("player2", true)
+
+		if notice := readUntil(t, ws, "opponent_reconnected"); notice.Data["playerID"] != "player2" {
+			t.Errorf("Expected player2 to be back, got %+v", notice)
+		}
+		clock.Advance(DefaultConfig().DisconnectGracePeriod)
+		if gm.IsOver() {
+			t.Error("Expected the game to go on")
+		}
+	})
+
+	t.Run("Nobody wins when both players are gone", func(t *testing.T) {
+		s, gm, ws, clock := start(t)
+		gm.SetPlayerConnected("player2", false)
+		ws.Close()
+		waitFor(t, func() bool { return hasGracePeriod(s, gm.ID, "player1") })
+
+		clock.Advance(DefaultConfig().DisconnectGracePeriod)
+		if result := gm.Result(); result.Winner != "" || result.Reason != game.EndReasonAborted {
+			t.Errorf("Expected the game to be aborted, got %+v", result)
+		}
+	})
+
+	t.Run("Restored games can be forfeited", func(t *testing.T) {
+		clock := game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+		store := game.NewMemoryStore()
+		saved, _ := game.NewManager(game.WithStore(store), game.WithClock(clock)).
+			CreateGame("player1", "player2", game.DefaultMatchConfig())
+
+		s := NewServerWithManager(game.NewManager(game.WithStore(store), game.WithClock(clock)))
+		if err := s.Restore(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		gm, _ := s.gm.GetGame(saved.ID)
+		gm.SetPlayerConnected("player2", true)
+
+		clock.Advance(DefaultConfig().DisconnectGracePeriod)
+		if result := gm.Result(); result.Winner != "player2" || result.Reason != game.EndReasonAbandoned {
+			t.Errorf("Expected player2 to win the restored game, got %+v", result)
+		}
+	})
+
+	t.Run("Players who leave forfeit", func(t *testing.T) {
+		s, gm, ws, clock := start(t)
+		ws.Close()
+		waitFor(t, func() bool { return hasGracePeriod(s, gm.ID, "player1") })
+
+		clock.Advance(DefaultConfig().DisconnectGracePeriod)
+		if result := gm.Result(); result.Winner != "player2" || result.Reason != game.EndReasonAbandoned {
+			t.Errorf("Expected player2 to win by abandonment, got %+v", result)
+		}
+	})
+}
+
+func TestMatchWhileSendingMoves(t *testing.T) {
+	s := NewServer()
+
+	// The connections need no socket: socket I/O would order the goroutines
+	// and hide races from the detector.
+	connect := func(playerID string) *Connection {
+		conn := &Connection{send: make(chan []byte, 256), playerID: playerID}
+		go func() {
+			for range conn.send {
+			}
+		}()
+		s.mu.Lock()
+		s.conns[playerID] = conn
+		s.mu.Unlock()
+		return conn
+	}
+	alice, bob := connect("player1"), connect("player2")
+	defer alice.close()
+	defer bob.close()
+
+	// Moves are handled on the connection's goroutine while the matcher
+	// seats it from another.
+	started := make(chan struct{})
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.handleMakeMove(alice, map[string]any{"move": "rock"})
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.handleMakeMove(alice, map[string]any{"move": "rock"})
+			}
+		}
+	}()
+	<-started
+
+	p1, p2 := &models.Player{ID: "player1"}, &models.Player{ID: "player2"}
+	s.handleMatch(matchmaking.Match{
+		P1:       p1,
+		P2:       p2,
+		P1Ticket: matchmaking.MatchTicket{Player: p1},
+		P2Ticket: matchmaking.MatchTicket{Player: p2},
+	})
+	close(stop)
+	<-done
+
+	if s.gameOf(alice) == "" || s.gameOf(alice) != s.gameOf(bob) {
+		t.Error("Expected both players to be seated in the same game")
+	}
+}
+
+func TestMatchWithDisconnectedPlayer(t *testing.T) {
+	s := NewServer()
+	alice := &Connection{send: make(chan []byte, 256), playerID: "player1"}
+	s.conns[alice.playerID] = alice
+
+	for i := 1; i <= 3; i++ {
+		s.queue.AddPlayer(&models.Player{ID: fmt.Sprintf("player%d", i)})
+	}
+	joinedAt := s.queue.Snapshot()[0].JoinedAt
+	matches := s.queue.TryMatchAll()
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+
+	// player2 has no connection, so player1 goes back to the queue.
+	s.handleMatch(matches[0])
+	queued := s.queue.Snapshot()
+	if len(queued) != 2 || queued[0].Player.ID != "player1" || !queued[0].JoinedAt.Equal(joinedAt) {
+		t.Errorf("Expected player1 back at the front with their original wait, got %+v", queued)
+	}
+}
+
+func TestMatchFailure(t *testing.T) {
+	s := NewServer()
+	alice, bob := bufferedConn(s, "player1"), bufferedConn(s, "player2")
+	p1, p2 := &models.Player{ID: "player1"}, &models.Player{ID: "player2"}
+	ticket := matchmaking.MatchTicket{Player: p1, RuleSet: "no-such-rules"}
+
+	s.handleMatch(matchmaking.Match{P1: p1, P2: p2, P1Ticket: ticket, P2Ticket: matchmaking.MatchTicket{Player: p2}})
+	for _, conn := range []*Connection{alice, bob} {
+		if msg := nextOfType(t, conn, "error"); msg.Data["message"] != `unknown rule set "no-such-rules"` {
+			t.Errorf("Expected %s to be told the match failed, got %+v", conn.playerID, msg)
+		}
+	}
+
+	s.handleBackfill(matchmaking.QueuedPlayer{Player: p1, Ticket: ticket})
+	if msg := nextOfType(t, alice, "error"); msg.Data["message"] != `unknown rule set "no-such-rules"` {
+		t.Errorf("Expected player1 to be told the bot game failed, got %+v", msg)
+	}
+	if s.gameOf(alice) != "" || s.gameOf(bob) != "" {
+		t.Error("Expected nobody to be seated in a game")
+	}
+}
+
+func TestJoinSecondGame(t *testing.T) {
+	s := NewServer()
+	conn := bufferedConn(s, "player1")
+	first, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+	second, _ := s.gm.CreateGame("player3", "player4", game.DefaultMatchConfig())
+
+	s.handleMessage(conn, &Message{Type: "join_game", Data: map[string]any{"game_id": first.ID}})
+	nextOfType(t, conn, "game_joined")
+
+	s.handleMessage(conn, &Message{Type: "join_game", Data: map[string]any{"game_id": second.ID, "spectate": true}})
+	if msg := nextOfType(t, conn, "error"); msg.Data["message"] != "already in a game" {
+		t.Errorf("Expected an already in a game error, got %+v", msg)
+	}
+	if s.gameOf(conn) != first.ID || !first.SpectatorView().P1Connected {
+		t.Error("Expected the player to stay in their game")
+	}
+
+	first.SetPlayerConnected("player2", true)
+	first.SetPlayerConnected("player2", false)
+	first.Abandon("player2")
+	s.handleMessage(conn, &Message{Type: "join_game", Data: map[string]any{"game_id": second.ID, "spectate": true}})
+	nextOfType(t, conn, "game_joined")
+	if s.gameOf(conn) != second.ID {
+		t.Error("Expected the player to move on once their game is over")
+	}
+}
+
+func TestReconnectWithoutResuming(t *testing.T) {
+	s, url, token := newTestServer(t, DefaultConfig())
+	gm, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+	gm.SetPlayerConnected("player2", true)
+
+	first, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+	if err != nil {
+		t.Fatalf("Expected the upgrade to succeed, got %v", err)
+	}
+	t.Cleanup(func() { first.Close() })
+	first.WriteJSON(Message{Type: "join_game", Data: map[string]any{"game_id": gm.ID}})
+	readUntil(t, first, "game_joined")
+	waitFor(t, func() bool { return gm.SpectatorView().P1Connected })
+
+	second, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+	if err != nil {
+		t.Fatalf("Expected the upgrade to succeed, got %v", err)
+	}
+	t.Cleanup(func() { second.Close() })
+	readUntil(t, second, "session")
+
+	waitFor(t, func() bool { return !gm.SpectatorView().P1Connected })
+	waitFor(t, func() bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		_, exists := s.graceTimers[graceKey{gameID: gm.ID, playerID: "player1"}]
+		return exists && len(s.gameConns[gm.ID]) == 0
+	})
+	if !isConnected(s, "player1") {
+		t.Error("Expected the new connection to stay registered")
+	}
+}
+
+func TestQueueTimeout(t *testing.T) {
+	clock := game.NewMockClock(time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+	s := NewServerWithClock(clock)
+	conn := bufferedConn(s, "player1")
+	s.queue.AddPlayer(&models.Player{ID: "player1"})
+
+	clock.Advance(DefaultMatchmakingConfig().QueueTimeout + time.Second)
+	timedOut := s.queue.CleanupTimeoutQueuePlayers(DefaultMatchmakingConfig().QueueTimeout)
+	if len(timedOut) != 1 {
+		t.Fatalf("Expected the server's clock to time player1 out, got %v", timedOut)
+	}
+
+	s.handleQueueTimeout(timedOut[0])
+	if msg := nextOfType(t, conn, "queue_timeout"); msg.Data["waitedSeconds"] != float64(301) {
+		t.Errorf("Expected player1 to have waited 301 seconds, got %+v", msg)
+	}
+}
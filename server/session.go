@@ -0,0 +1,183 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"ldriko/rps-backend/game"
+	"log"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// session outlives a player's connection so that a client whose socket drops
+// can resume where it left off. It numbers every message sent to the player
+// and keeps the latest ones to replay on resume.
+type session struct {
+	token    string
+	playerID string
+	// conn is the connection the session last belonged to. Once dropped it
+	// stays in its game's connections, so the messages it misses are still
+	// numbered and kept.
+	conn *Connection
+	// expiry forgets the session when a dropped player does not resume in
+	// time.
+	expiry game.Timer
+
+	seq    uint64
+	buffer [][]byte
+	size   int
+	mu     sync.Mutex
+}
+
+func newSession(conn *Connection, size int) *session {
+	token := make([]byte, 32)
+	rand.Read(token)
+
+	return &session{
+		token:    hex.EncodeToString(token),
+		playerID: conn.playerID,
+		conn:     conn,
+		size:     size,
+	}
+}
+
+// record numbers the message, keeps it for replay and returns it encoded.
+func (ss *session) record(msg Message) ([]byte, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	msg.Seq = ss.seq + 1
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.seq++
+	ss.buffer = append(ss.buffer, data)
+	if len(ss.buffer) > ss.size {
+		ss.buffer = ss.buffer[len(ss.buffer)-ss.size:]
+	}
+	return data, nil
+}
+
+// since returns the messages numbered after lastSeq. It reports false when
+// some of them are no longer buffered.
+func (ss *session) since(lastSeq uint64) ([][]byte, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if lastSeq > ss.seq {
+		return nil, false
+	}
+	missed := int(ss.seq - lastSeq)
+	if missed > len(ss.buffer) {
+		return nil, false
+	}
+	return append([][]byte(nil), ss.buffer[len(ss.buffer)-missed:]...), true
+}
+
+// resumeRequest is what a reconnecting client sends to pick up its session.
+type resumeRequest struct {
+	token   string
+	lastSeq uint64
+}
+
+// resumeRequestFrom reads the resume_token and last_seq query parameters. A
+// missing or malformed last_seq counts as having seen nothing.
+func resumeRequestFrom(query url.Values) resumeRequest {
+	lastSeq, _ := strconv.ParseUint(query.Get("last_seq"), 10, 64)
+	return resumeRequest{token: query.Get("resume_token"), lastSeq: lastSeq}
+}
+
+// attachSession gives the connection the player's session when the resume
+// token matches it, and a fresh session otherwise. It reports whether the
+// session was resumed. It must be called with s.mu held, which keeps
+// broadcasts from overtaking the replay.
+func (s *Server) attachSession(conn *Connection, req resumeRequest) bool {
+	ss, exists := s.sessions[conn.playerID]
+	if !exists || req.token == "" || subtle.ConstantTimeCompare([]byte(req.token), []byte(ss.token)) != 1 {
+		conn.session = newSession(conn, s.config.ResumeBufferSize)
+		s.sessions[conn.playerID] = conn.session
+		conn.sendUnrecorded(sessionMessage(conn.session, false))
+		return false
+	}
+
+	if ss.expiry != nil {
+		ss.expiry.Stop()
+		ss.expiry = nil
+	}
+	old := ss.conn
+	ss.conn = conn
+	conn.session = ss
+
+	// The new connection takes the old one's place in its game.
+	if old != nil && old.gameID != "" {
+		conn.gameID = old.gameID
+		for i, c := range s.gameConns[old.gameID] {
+			if c == old {
+				s.gameConns[old.gameID][i] = conn
+			}
+		}
+		old.gameID = ""
+	}
+
+	conn.sendUnrecorded(sessionMessage(ss, true))
+	if missed, ok := ss.since(req.lastSeq); ok {
+		conn.sendEncoded(missed)
+		return true
+	}
+
+	data := map[string]any{"gameID": conn.gameID}
+	if gm, exists := s.gm.GetGame(conn.gameID); exists {
+		data["seat"] = gm.SeatOf(conn.playerID)
+		data["game"] = gm.ViewFor(conn.playerID)
+	}
+	conn.SendMessage(Message{Type: "state_sync", Data: data})
+	return true
+}
+
+func sessionMessage(ss *session, resumed bool) Message {
+	return Message{
+		Type: "session",
+		Data: map[string]any{
+			"resumeToken": ss.token,
+			"resumed":     resumed,
+		},
+	}
+}
+
+// detachSession keeps the session of a dropped connection for the resume
+// window.
+func (s *Server) detachSession(conn *Connection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := conn.session
+	if ss == nil || ss.conn != conn {
+		return
+	}
+	ss.expiry = s.clock.AfterFunc(s.config.ResumeWindow, func() {
+		s.expireSession(ss)
+	})
+}
+
+// expireSession forgets a session that was not resumed in time, along with
+// the dropped connection kept in its game.
+func (s *Server) expireSession(ss *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conns[ss.playerID] == ss.conn {
+		return
+	}
+	if s.sessions[ss.playerID] == ss {
+		delete(s.sessions, ss.playerID)
+	}
+	if ss.conn.gameID != "" {
+		s.removeConnFromGame(ss.conn, ss.conn.gameID)
+	}
+	log.Printf("session of player %s expired", ss.playerID)
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"ldriko/rps-backend/game"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSessionBuffer(t *testing.T) {
+	ss := newSession(&Connection{playerID: "player1"}, 2)
+	for _, msgType := range []string{"one", "two", "three"} {
+		ss.record(Message{Type: msgType})
+	}
+
+	missed, ok := ss.since(1)
+	if !ok || len(missed) != 2 || string(missed[0]) != `{"type":"two","data":null,"seq":2}` {
+		t.Errorf("Expected messages 2 and 3, got %q, %v", missed, ok)
+	}
+	if missed, ok := ss.since(3); !ok || len(missed) != 0 {
+		t.Errorf("Expected nothing to be missed, got %q, %v", missed, ok)
+	}
+	if _, ok := ss.since(0); ok {
+		t.Error("Expected a dropped message to need a snapshot")
+	}
+	if _, ok := ss.since(4); ok {
+		t.Error("Expected a sequence number from the future to need a snapshot")
+	}
+}
+
+// readUntil reads messages from ws until one of type msgType arrives.
+func readUntil(t *testing.T, ws *websocket.Conn, msgType string) Message {
+	t.Helper()
+
+	for {
+		var msg Message
+		if err := ws.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected a %s message, got %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	// connect joins player1 to a game with player2, then drops the
+	// connection. It returns the game, the resume token and the sequence
+	// number of the last message received.
+	connect := func(t *testing.T, s *Server, url, token string) (*game.Game, string, uint64) {
+		t.Helper()
+
+		gm, _ := s.gm.CreateGame("player1", "player2", game.DefaultMatchConfig())
+		ws, _, err := websocket.DefaultDialer.Dial(url+"?token="+token, http.Header{})
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+
+		session := readUntil(t, ws, "session")
+		if session.Data["resumed"] != false || session.Seq != 0 {
+			t.Errorf("Expected a new, unnumbered session message, got %+v", session)
+		}
+		ws.WriteJSON(Message{Type: "join_game", Data: map[string]any{"game_id": gm.ID}})
+		joined := readUntil(t, ws, "game_joined")
+		waitFor(t, func() bool { return gm.SpectatorView().P1Connected })
+
+		ws.Close()
+		waitFor(t, func() bool { return !isConnected(s, "player1") })
+		return gm, session.Data["resumeToken"].(string), joined.Seq
+	}
+
+	resume := func(t *testing.T, url, token, resumeToken string, lastSeq uint64) *websocket.Conn {
+		t.Helper()
+
+		ws, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s?token=%s&resume_token=%s&last_seq=%d", url, token, resumeToken, lastSeq), http.Header{})
+		if err != nil {
+			t.Fatalf("Expected the upgrade to succeed, got %v", err)
+		}
+		t.Cleanup(func() { ws.Close() })
+		return ws
+	}
+
+	t.Run("Missed messages are replayed", func(t *testing.T) {
+		s, url, token := newTestServer(t, DefaultConfig())
+		gm, resumeToken, lastSeq := connect(t, s, url, token)
+
+		gm.SetPlayerConnected("player2", true)
+		ws := resume(t, url, token, resumeToken, lastSeq)
+
+		session := readUntil(t, ws, "session")
+		if session.Data["resumed"] != true || session.Data["resumeToken"] != resumeToken {
+			t.Errorf("Expected the session to be resumed, got %+v", session)
+		}
+
+		var missed Message
+		ws.ReadJSON(&missed)
+		if missed.Type != "player_joined" || missed.Seq != lastSeq+1 || missed.Data["playerID"] != "player2" {
+			t.Errorf("Expected the missed player_joined message, got %+v", missed)
+		}
+
+		waitFor(t, func() bool { return gm.SpectatorView().P1Connected })
+		gm.SetPlayerConnected("player2", false)
+		if left := readUntil(t, ws, "player_left"); left.Seq != lastSeq+2 {
+			t.Errorf("Expected numbering to carry on, got %d", left.Seq)
+		}
+	})
+
+	t.Run("Overflowing the buffer sends a snapshot", func(t *testing.T) {
+		config := DefaultConfig()
+		config.ResumeBufferSize = 1
+		s, url, token := newTestServer(t, config)
+		gm, resumeToken, lastSeq := connect(t, s, url, token)
+
+		gm.SetPlayerConnected("player2", true)
+		gm.SetPlayerConnected("player2", false)
+		ws := resume(t, url, token, resumeToken, lastSeq)
+
+		readUntil(t, ws, "session")
+		var sync Message
+		ws.ReadJSON(&sync)
+		if sync.Type != "state_sync" || sync.Data["gameID"] != gm.ID || sync.Data["seat"] != string(game.SeatP1) {
+			t.Errorf("Expected a state_sync snapshot of the game, got %+v", sync)
+		}
+		if sync.Seq <= lastSeq+2 {
+			t.Errorf("Expected the snapshot to follow the dropped messages, got %d", sync.Seq)
+		}
+	})
+
+	t.Run("Unknown tokens start a new session", func(t *testing.T) {
+		s, url, token := newTestServer(t, DefaultConfig())
+		gm, resumeToken, lastSeq := connect(t, s, url, token)
+
+		ws := resume(t, url, token, "not-the-token", lastSeq)
+		session := readUntil(t, ws, "session")
+		if session.Data["resumed"] != false || session.Data["resumeToken"] == resumeToken {
+			t.Errorf("Expected a new session, got %+v", session)
+		}
+		if gm.SpectatorView().P1Connected {
+			t.Error("Expected the player to stay disconnected until they rejoin")
+		}
+	})
+
+	t.Run("Sessions expire", func(t *testing.T) {
+		clock := game.NewMockClock(game.RealClock{}.Now())
+		s, url, token := newTestServer(t, DefaultConfig(), game.WithClock(clock))
+		gm, resumeToken, lastSeq := connect(t, s, url, token)
+
+		clock.Advance(DefaultConfig().ResumeWindow)
+		waitFor(t, func() bool {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			return len(s.gameConns[gm.ID]) == 0
+		})
+
+		ws := resume(t, url, token, resumeToken, lastSeq)
+		if session := readUntil(t, ws, "session"); session.Data["resumed"] != false {
+			t.Errorf("Expected an expired session not to resume, got %+v", session)
+		}
+	})
+}